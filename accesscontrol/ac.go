@@ -1,8 +1,10 @@
 package accesscontrol
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/avenga/couper/config/request"
 	"github.com/avenga/couper/eval"
 )
 
@@ -43,3 +45,19 @@ func (m Map) MustExist(name string) {
 		panic("accessControl is not defined: " + name)
 	}
 }
+
+// setAccessControlContext stores the given data for the named access control
+// within the request context which makes them available as 'req.ctx.<name>'.
+func setAccessControlContext(req *http.Request, name string, data map[string]interface{}) {
+	ctx := req.Context()
+	// copy the map, the context of a failed group branch must not leak into its parent request
+	parent, _ := ctx.Value(request.AccessControls).(map[string]interface{})
+	acMap := make(map[string]interface{}, len(parent)+1)
+	for k, v := range parent {
+		acMap[k] = v
+	}
+	acMap[name] = data
+
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+	*req = *req.WithContext(ctx)
+}
@@ -0,0 +1,197 @@
+package accesscontrol
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const apiKeyPrefixSHA256 = "sha256:"
+
+var (
+	ErrorAPIKeyInvalid = errors.New("api_key: invalid key")
+
+	_ AccessControl = &APIKey{}
+)
+
+// APIKeyEntry represents a known api key. The Hash is either a "sha256:<hex>" digest of
+// the key or a bcrypt hash of the secret of a "<id>.<secret>" key.
+type APIKeyEntry struct {
+	Hash     string            `json:"hash"`
+	ID       string            `json:"id"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// APIKeyOptions represents the configuration of an <APIKey> access control.
+// The optional File contains the hashed keys in JSON format and is reloaded on changes.
+type APIKeyOptions struct {
+	File      string
+	Keys      []*APIKeyEntry
+	Log       *logrus.Entry
+	Name      string
+	Source    Source
+	SourceKey string
+}
+
+// APIKey validates static api keys against the configured keys and the keys of the keys file.
+type APIKey struct {
+	file    *reloadableFile
+	inline  *apiKeyStore
+	options *APIKeyOptions
+}
+
+// apiKeyStore holds the entries by their sha256 digest and the bcrypt entries by their id,
+// so that a key is compared with one bcrypt hash at most. Verified keys are cached by their digest.
+type apiKeyStore struct {
+	bcrypt   map[string]*APIKeyEntry
+	digests  map[string]*APIKeyEntry
+	verified sync.Map
+}
+
+// NewAPIKey creates a new <*APIKey> object.
+func NewAPIKey(opts *APIKeyOptions) (*APIKey, error) {
+	if opts.Source != Cookie && opts.Source != Header && opts.Source != QueryParam {
+		return nil, ErrorUnknownSource
+	}
+
+	if len(opts.Keys) == 0 && opts.File == "" {
+		return nil, fmt.Errorf("api_key: either key or keys_file must be specified")
+	}
+
+	inline, err := newAPIKeyStore(opts.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &APIKey{inline: inline, options: opts}
+
+	if opts.File != "" {
+		a.file, err = newReloadableFile(opts.File, parseAPIKeysFile, opts.Log)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Validate implements the <AccessControl> interface.
+func (a *APIKey) Validate(req *http.Request) error {
+	var key string
+	switch a.options.Source {
+	case Cookie:
+		if cookie, err := req.Cookie(a.options.SourceKey); err == nil {
+			key = cookie.Value
+		}
+	case Header:
+		key = req.Header.Get(a.options.SourceKey)
+	case QueryParam:
+		key = req.URL.Query().Get(a.options.SourceKey)
+	}
+
+	if key == "" {
+		return ErrorEmptyToken
+	}
+
+	entry := a.inline.lookup(key)
+	if entry == nil && a.file != nil {
+		entry = a.file.get().(*apiKeyStore).lookup(key)
+	}
+
+	if entry == nil {
+		return ErrorAPIKeyInvalid
+	}
+
+	data := map[string]interface{}{"id": entry.ID}
+	for k, v := range entry.Metadata {
+		data[k] = v
+	}
+	setAccessControlContext(req, a.options.Name, data)
+
+	return nil
+}
+
+func newAPIKeyStore(entries []*APIKeyEntry) (*apiKeyStore, error) {
+	store := &apiKeyStore{
+		bcrypt:  make(map[string]*APIKeyEntry),
+		digests: make(map[string]*APIKeyEntry),
+	}
+	for _, entry := range entries {
+		switch {
+		case strings.HasPrefix(entry.Hash, apiKeyPrefixSHA256):
+			digest := strings.ToLower(strings.TrimPrefix(entry.Hash, apiKeyPrefixSHA256))
+			if b, err := hex.DecodeString(digest); err != nil || len(b) != sha256.Size {
+				return nil, fmt.Errorf("api_key: key %q: invalid sha256 hash", entry.ID)
+			}
+			if _, exist := store.digests[digest]; exist {
+				return nil, fmt.Errorf("api_key: key %q: duplicate key", entry.ID)
+			}
+			store.digests[digest] = entry
+		case getPwdType(entry.Hash) == pwdTypeBcrypt:
+			if entry.ID == "" || strings.Contains(entry.ID, ".") {
+				return nil, fmt.Errorf("api_key: key %q: bcrypt keys require an id without dots", entry.ID)
+			}
+			if _, exist := store.bcrypt[entry.ID]; exist {
+				return nil, fmt.Errorf("api_key: key %q: duplicate id", entry.ID)
+			}
+			store.bcrypt[entry.ID] = entry
+		default:
+			return nil, fmt.Errorf("api_key: key %q: unsupported hash, expected sha256 or bcrypt", entry.ID)
+		}
+	}
+	return store, nil
+}
+
+func (s *apiKeyStore) lookup(key string) *APIKeyEntry {
+	sum := sha256.Sum256([]byte(key))
+	digest := hex.EncodeToString(sum[:])
+
+	if entry, exist := s.digests[digest]; exist {
+		return entry
+	}
+
+	if entry, exist := s.verified.Load(digest); exist {
+		return entry.(*APIKeyEntry)
+	}
+
+	// bcrypt keys have the "<id>.<secret>" format
+	i := strings.Index(key, ".")
+	if i < 0 {
+		return nil
+	}
+
+	entry, exist := s.bcrypt[key[:i]]
+	if !exist || bcrypt.CompareHashAndPassword([]byte(entry.Hash), []byte(key[i+1:])) != nil {
+		return nil
+	}
+
+	s.verified.Store(digest, entry)
+	return entry
+}
+
+// HashAPIKey returns the given value as "sha256:<hex>" digest unless it is already
+// a supported api key hash.
+func HashAPIKey(value string) string {
+	if strings.HasPrefix(value, apiKeyPrefixSHA256) || getPwdType(value) == pwdTypeBcrypt {
+		return value
+	}
+	sum := sha256.Sum256([]byte(value))
+	return apiKeyPrefixSHA256 + hex.EncodeToString(sum[:])
+}
+
+func parseAPIKeysFile(content []byte) (interface{}, error) {
+	file := &struct {
+		Keys []*APIKeyEntry `json:"keys"`
+	}{}
+	if err := json.Unmarshal(content, file); err != nil {
+		return nil, fmt.Errorf("api_key: invalid keys_file: %v", err)
+	}
+	return newAPIKeyStore(file.Keys)
+}
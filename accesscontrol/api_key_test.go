@@ -0,0 +1,151 @@
+package accesscontrol_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config/request"
+)
+
+func TestAPIKey_Validate(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("bcrypt-secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "api_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keysFile := filepath.Join(dir, "keys.json")
+	writeKeys := func(content string) {
+		if err := ioutil.WriteFile(keysFile, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeKeys(`{"keys":[
+		{"id": "file", "hash": "` + ac.HashAPIKey("file-key") + `", "metadata": {"plan": "silver"}},
+		{"id": "bcrypt", "hash": "` + string(bcryptHash) + `"}
+	]}`)
+
+	apiKey, err := ac.NewAPIKey(&ac.APIKeyOptions{
+		File: keysFile,
+		Keys: []*ac.APIKeyEntry{
+			{Hash: ac.HashAPIKey("inline-key"), ID: "acme", Metadata: map[string]string{"owner": "ACME", "plan": "gold"}},
+		},
+		Name:      "partner",
+		Source:    ac.Header,
+		SourceKey: "X-API-Key",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(key string) (map[string]interface{}, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		if err := apiKey.Validate(req); err != nil {
+			return nil, err
+		}
+		acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+		data, _ := acMap["partner"].(map[string]interface{})
+		return data, nil
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		wantID  string
+		wantErr error
+	}{
+		{"inline", "inline-key", "acme", nil},
+		{"file", "file-key", "file", nil},
+		{"bcrypt", "bcrypt.bcrypt-secret", "bcrypt", nil},
+		{"bcrypt cached", "bcrypt.bcrypt-secret", "bcrypt", nil},
+		{"bcrypt /w wrong secret", "bcrypt.other-secret", "", ac.ErrorAPIKeyInvalid},
+		{"bcrypt /w unknown id", "other.bcrypt-secret", "", ac.ErrorAPIKeyInvalid},
+		{"bcrypt /w secret only", "bcrypt-secret", "", ac.ErrorAPIKeyInvalid},
+		{"unknown", "unknown-key", "", ac.ErrorAPIKeyInvalid},
+		{"missing", "", "", ac.ErrorEmptyToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			data, err := validate(tt.key)
+			if err != tt.wantErr {
+				subT.Fatalf("expected error %v, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && data["id"] != tt.wantID {
+				subT.Errorf("expected id %q, got: %v", tt.wantID, data["id"])
+			}
+		})
+	}
+
+	if data, _ := validate("inline-key"); data["owner"] != "ACME" || data["plan"] != "gold" {
+		t.Errorf("expected the key metadata, got: %v", data)
+	}
+
+	// reload
+	reloadInterval := ac.FileReloadInterval
+	ac.FileReloadInterval = 0
+	defer func() { ac.FileReloadInterval = reloadInterval }()
+
+	// ensure a different modification time
+	time.Sleep(time.Millisecond * 10)
+	writeKeys(`{"keys":[{"id": "rotated", "hash": "` + ac.HashAPIKey("rotated-key") + `"}]}`)
+
+	if _, err = validate("file-key"); err != ac.ErrorAPIKeyInvalid {
+		t.Errorf("expected the removed key to be invalid, got: %v", err)
+	}
+	if data, rerr := validate("rotated-key"); rerr != nil || data["id"] != "rotated" {
+		t.Errorf("expected the reloaded key, got: %v, %v", data, rerr)
+	}
+
+	// invalid content keeps the current keys
+	time.Sleep(time.Millisecond * 10)
+	writeKeys(`{"keys":[{"id": "plain", "hash": "plain-key"}]}`)
+
+	if _, err = validate("rotated-key"); err != nil {
+		t.Errorf("expected the current keys after an invalid reload, got: %v", err)
+	}
+}
+
+func TestNewAPIKey_Errors(t *testing.T) {
+	const bcryptHash = "$2y$10$4cGhB6p7Gbe9iR.4.a2Qv.aq1vuD8wyjH3nBQ3Jm6iFxUJlQK4SGe"
+
+	tests := []struct {
+		name    string
+		keys    []*ac.APIKeyEntry
+		wantErr string
+	}{
+		{"plain", []*ac.APIKeyEntry{{Hash: "plain-key", ID: "plain"}}, `api_key: key "plain": unsupported hash, expected sha256 or bcrypt`},
+		{"bcrypt id", []*ac.APIKeyEntry{{Hash: bcryptHash, ID: "a.b"}}, `api_key: key "a.b": bcrypt keys require an id without dots`},
+		{"bcrypt duplicate", []*ac.APIKeyEntry{{Hash: bcryptHash, ID: "a"}, {Hash: bcryptHash, ID: "a"}}, `api_key: key "a": duplicate id`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := ac.NewAPIKey(&ac.APIKeyOptions{
+				Keys:      tt.keys,
+				Name:      "partner",
+				Source:    ac.Header,
+				SourceKey: "X-API-Key",
+			})
+			if err == nil || err.Error() != tt.wantErr {
+				subT.Errorf("expected error %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
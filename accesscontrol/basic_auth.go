@@ -2,12 +2,16 @@ package accesscontrol
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/sha1"
 	"crypto/subtle"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
+
+	"github.com/sirupsen/logrus"
 )
 
 var _ AccessControl = &BasicAuth{}
@@ -52,36 +56,48 @@ func (e BasicAuthError) Error() string {
 	return e.error.Error()
 }
 
+func (e BasicAuthError) Unwrap() error {
+	return e.error
+}
+
 // BasicAuth represents an AC-BasicAuth object
 type BasicAuth struct {
-	htFile htData
+	htFile *reloadableFile
 	name   string
 	user   string
 	pass   string
 	realm  string
 }
 
-// NewBasicAuth creates a new AC-BasicAuth object
-func NewBasicAuth(name, user, pass, file, realm string) (*BasicAuth, error) {
+// NewBasicAuth creates a new AC-BasicAuth object. The optional htpasswd file
+// is reloaded on changes, an invalid file keeps the current users.
+func NewBasicAuth(name, user, pass, file, realm string, log *logrus.Entry) (*BasicAuth, error) {
 	ba := &BasicAuth{
-		htFile: make(htData),
-		name:   name,
-		user:   user,
-		pass:   pass,
-		realm:  realm,
+		name:  name,
+		user:  user,
+		pass:  pass,
+		realm: realm,
 	}
 
 	if file == "" {
 		return ba, nil
 	}
 
-	fp, err := os.Open(file)
+	htFile, err := newReloadableFile(file, func(content []byte) (interface{}, error) {
+		return parseHTFile(file, content)
+	}, log)
 	if err != nil {
 		return nil, err
 	}
-	defer fp.Close()
 
-	scanner := bufio.NewScanner(fp)
+	ba.htFile = htFile
+	return ba, nil
+}
+
+func parseHTFile(file string, content []byte) (htData, error) {
+	data := make(htData)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
 	var lineNr int
 	for scanner.Scan() {
 		lineNr++
@@ -99,10 +115,12 @@ func NewBasicAuth(name, user, pass, file, realm string) (*BasicAuth, error) {
 			return nil, &BasicAuthHTParseError{code: InvalidLine, error: fmt.Errorf("%s:%d", file, lineNr)}
 		}
 
-		if _, ok := ba.htFile[up[0]]; ok {
+		if _, ok := data[up[0]]; ok {
 			return nil, &BasicAuthHTParseError{code: MultipleUser, error: fmt.Errorf("%s:%d: %q", file, lineNr, up[0])}
 		}
 
+		malformed := &BasicAuthHTParseError{code: MalformedPassword, error: fmt.Errorf("%s:%d: user %q", file, lineNr, up[0])}
+
 		switch pwdType := getPwdType(up[1]); pwdType {
 		case pwdTypeApr1:
 			fallthrough
@@ -114,20 +132,62 @@ func NewBasicAuth(name, user, pass, file, realm string) (*BasicAuth, error) {
 
 			parts := strings.Split(strings.TrimPrefix(up[1], prefix), "$")
 			if len(parts) != 2 {
-				return nil, &BasicAuthHTParseError{code: MalformedPassword, error: fmt.Errorf("%s:%d: user %q", file, lineNr, up[0])}
+				return nil, malformed
 			}
 
-			ba.htFile[up[0]] = pwd{
+			data[up[0]] = pwd{
 				pwdOrig:   []byte(up[1]),
 				pwdPrefix: prefix,
 				pwdSalt:   parts[0],
 				pwdType:   pwdType,
 			}
+		case pwdTypeArgon2id:
+			params, err := parseArgon2id(up[1])
+			if err != nil {
+				return nil, malformed
+			}
+
+			data[up[0]] = pwd{
+				pwdArgon2: params,
+				pwdOrig:   []byte(up[1]),
+				pwdType:   pwdType,
+			}
 		case pwdTypeBcrypt:
-			ba.htFile[up[0]] = pwd{
+			data[up[0]] = pwd{
+				pwdOrig: []byte(up[1]),
+				pwdType: pwdType,
+			}
+		case pwdTypeSHA:
+			digest, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(up[1], pwdPrefixSHA))
+			if err != nil || len(digest) != sha1.Size {
+				return nil, malformed
+			}
+
+			data[up[0]] = pwd{
 				pwdOrig: []byte(up[1]),
 				pwdType: pwdType,
 			}
+		case pwdTypeSHA256:
+			fallthrough
+		case pwdTypeSHA512:
+			prefix := pwdPrefixSHA256
+			if pwdType == pwdTypeSHA512 {
+				prefix = pwdPrefixSHA512
+			}
+
+			// [rounds=<n>$]<salt>$<hash>
+			settings := strings.TrimPrefix(up[1], prefix)
+			i := strings.LastIndex(settings, "$")
+			if i < 0 {
+				return nil, malformed
+			}
+
+			data[up[0]] = pwd{
+				pwdOrig:   []byte(up[1]),
+				pwdPrefix: prefix,
+				pwdSalt:   settings[:i],
+				pwdType:   pwdType,
+			}
 		default:
 			return nil, &BasicAuthHTParseError{
 				code:  NotSupported,
@@ -136,8 +196,7 @@ func NewBasicAuth(name, user, pass, file, realm string) (*BasicAuth, error) {
 		}
 	}
 
-	err = scanner.Err()
-	return ba, err
+	return data, scanner.Err()
 }
 
 // Validate implements the AccessControl interface
@@ -146,7 +205,7 @@ func (ba *BasicAuth) Validate(req *http.Request) error {
 		return ErrorBasicAuthNotConfigured
 	}
 
-	if ba.pass == "" {
+	if ba.pass == "" && ba.htFile == nil {
 		return &BasicAuthError{error: ErrorBasicAuthUnauthorized, Realm: ba.realm}
 	}
 
@@ -155,14 +214,14 @@ func (ba *BasicAuth) Validate(req *http.Request) error {
 		return &BasicAuthError{error: ErrorBasicAuthMissingCredentials, Realm: ba.realm}
 	}
 
-	if ba.user == user {
+	if ba.pass != "" && ba.user == user {
 		if subtle.ConstantTimeCompare([]byte(ba.pass), []byte(pass)) == 1 {
 			return nil
 		}
 		return &BasicAuthError{error: ErrorBasicAuthUnauthorized, Realm: ba.realm}
 	}
 
-	if validateAccessData(user, pass, ba.htFile) {
+	if ba.htFile != nil && validateAccessData(user, pass, ba.htFile.get().(htData)) {
 		return nil
 	}
 
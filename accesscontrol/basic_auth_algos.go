@@ -3,26 +3,52 @@ package accesscontrol
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
 	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	pwdPrefixApr1     = "$apr1$"
-	pwdPrefixBcrypt2a = "$2a$"
-	pwdPrefixBcrypt2b = "$2b$"
-	pwdPrefixBcrypt2x = "$2x$"
-	pwdPrefixBcrypt2y = "$2y$"
-	pwdPrefixMD5      = "$1$"
+	pwdPrefixApr1      = "$apr1$"
+	pwdPrefixArgon2id  = "$argon2id$"
+	pwdPrefixBcrypt2a  = "$2a$"
+	pwdPrefixBcrypt2b  = "$2b$"
+	pwdPrefixBcrypt2x  = "$2x$"
+	pwdPrefixBcrypt2y  = "$2y$"
+	pwdPrefixMD5       = "$1$"
+	pwdPrefixSHA       = "{SHA}"
+	pwdPrefixSHA256    = "$5$"
+	pwdPrefixSHA512    = "$6$"
+	shaCryptRounds     = 5000
+	shaCryptRoundsMax  = 999999999
+	shaCryptRoundsMin  = 1000
+	shaCryptSaltMaxLen = 16
 )
 
 const (
 	pwdTypeUnknown = iota
 	pwdTypeApr1
+	pwdTypeArgon2id
 	pwdTypeBcrypt
 	pwdTypeMD5
+	pwdTypeSHA
+	pwdTypeSHA256
+	pwdTypeSHA512
+)
+
+// argon2id cost limits, the parameters of an htpasswd entry apply to each login attempt
+const (
+	argon2MaxMemory = 1 << 20 // KiB
+	argon2MaxTime   = 100
 )
 
 const (
@@ -33,22 +59,50 @@ const (
 
 var pwdPrefixes = map[string]int{
 	pwdPrefixApr1:     pwdTypeApr1,
+	pwdPrefixArgon2id: pwdTypeArgon2id,
 	pwdPrefixBcrypt2a: pwdTypeBcrypt,
 	pwdPrefixBcrypt2b: pwdTypeBcrypt,
 	pwdPrefixBcrypt2x: pwdTypeBcrypt,
 	pwdPrefixBcrypt2y: pwdTypeBcrypt,
 	pwdPrefixMD5:      pwdTypeMD5,
+	pwdPrefixSHA:      pwdTypeSHA,
+	pwdPrefixSHA256:   pwdTypeSHA256,
+	pwdPrefixSHA512:   pwdTypeSHA512,
 }
 
+// byte order of the sha-crypt digest encoding, three bytes per group
+var (
+	sha256CryptOrder = [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+	}
+	sha512CryptOrder = [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41},
+	}
+)
+
 type htData map[string]pwd
 
 type pwd struct {
+	pwdArgon2 *argon2Params
 	pwdOrig   []byte
 	pwdPrefix string
 	pwdSalt   string
 	pwdType   int
 }
 
+type argon2Params struct {
+	hash    []byte
+	memory  uint32
+	salt    []byte
+	threads uint8
+	time    uint32
+}
+
 func getPwdType(pass string) int {
 	for p, t := range pwdPrefixes {
 		if strings.HasPrefix(pass, p) {
@@ -69,10 +123,26 @@ func validateAccessData(plainUser, plainPass string, data htData) bool {
 				if subtle.ConstantTimeCompare(apr1MD5(plainPass, pass.pwdSalt, pass.pwdPrefix), pass.pwdOrig) == 1 {
 					return true
 				}
+			case pwdTypeArgon2id:
+				p := pass.pwdArgon2
+				key := argon2.IDKey([]byte(plainPass), p.salt, p.time, p.memory, p.threads, uint32(len(p.hash)))
+				if subtle.ConstantTimeCompare(key, p.hash) == 1 {
+					return true
+				}
 			case pwdTypeBcrypt:
 				if err := bcrypt.CompareHashAndPassword(pass.pwdOrig, []byte(plainPass)); err == nil {
 					return true
 				}
+			case pwdTypeSHA:
+				sum := sha1.Sum([]byte(plainPass))
+				digest := pwdPrefixSHA + base64.StdEncoding.EncodeToString(sum[:])
+				if subtle.ConstantTimeCompare([]byte(digest), pass.pwdOrig) == 1 {
+					return true
+				}
+			case pwdTypeSHA256, pwdTypeSHA512:
+				if subtle.ConstantTimeCompare(shaCrypt(plainPass, pass.pwdSalt, pass.pwdPrefix), pass.pwdOrig) == 1 {
+					return true
+				}
 			}
 		}
 	}
@@ -164,3 +234,178 @@ func apr1MD5(pass, salt, pref string) []byte {
 
 	return buf.Bytes()
 }
+
+// parseArgon2id parses the encoded "$argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>" value.
+func parseArgon2id(value string) (*argon2Params, error) {
+	parts := strings.Split(strings.TrimPrefix(value, pwdPrefixArgon2id), "$")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid format")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[0], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("unsupported version: %q", parts[0])
+	}
+
+	p := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[1], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %q", parts[1])
+	}
+
+	if p.time < 1 || p.time > argon2MaxTime {
+		return nil, fmt.Errorf("time must be between 1 and %d: %d", argon2MaxTime, p.time)
+	}
+
+	if p.threads < 1 {
+		return nil, fmt.Errorf("parallelism must be greater than 0")
+	}
+
+	if p.memory < 8*uint32(p.threads) || p.memory > argon2MaxMemory {
+		return nil, fmt.Errorf("memory must be between %d and %d: %d", 8*uint32(p.threads), argon2MaxMemory, p.memory)
+	}
+
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[2]); err != nil {
+		return nil, fmt.Errorf("invalid salt: %v", err)
+	}
+
+	if p.hash, err = base64.RawStdEncoding.DecodeString(parts[3]); err != nil || len(p.hash) == 0 {
+		return nil, fmt.Errorf("invalid hash")
+	}
+	return p, nil
+}
+
+// shaCrypt implements the SHA-256 ("$5$") and SHA-512 ("$6$") variants of crypt(3),
+// see https://www.akkadia.org/drepper/SHA-crypt.txt. The settings contain the salt
+// with an optional "rounds=<n>$" prefix.
+func shaCrypt(pass, settings, pref string) []byte {
+	newHash, order := sha256.New, sha256CryptOrder
+	if pref == pwdPrefixSHA512 {
+		newHash, order = sha512.New, sha512CryptOrder
+	}
+
+	rounds, customRounds := shaCryptRounds, false
+	salt := settings
+	if strings.HasPrefix(salt, "rounds=") {
+		if i := strings.Index(salt, "$"); i > 0 {
+			if n, err := strconv.ParseUint(salt[len("rounds="):i], 10, 32); err == nil {
+				rounds, customRounds = int(n), true
+				salt = salt[i+1:]
+			}
+		}
+	}
+
+	if rounds < shaCryptRoundsMin {
+		rounds = shaCryptRoundsMin
+	} else if rounds > shaCryptRoundsMax {
+		rounds = shaCryptRoundsMax
+	}
+
+	if len(salt) > shaCryptSaltMaxLen {
+		salt = salt[:shaCryptSaltMaxLen]
+	}
+
+	p, s := []byte(pass), []byte(salt)
+
+	h := newHash()
+	h.Write(p)
+	h.Write(s)
+	h.Write(p)
+	alternate := h.Sum(nil)
+
+	h.Reset()
+	h.Write(p)
+	h.Write(s)
+	writeRepeated(h, alternate, len(p))
+	for i := len(p); i > 0; i >>= 1 {
+		if (i & 1) == 1 {
+			h.Write(alternate)
+		} else {
+			h.Write(p)
+		}
+	}
+	sum := h.Sum(nil)
+
+	h.Reset()
+	for i := 0; i < len(p); i++ {
+		h.Write(p)
+	}
+	pSeq := repeatBytes(h.Sum(nil), len(p))
+
+	h.Reset()
+	for i := 0; i < 16+int(sum[0]); i++ {
+		h.Write(s)
+	}
+	sSeq := repeatBytes(h.Sum(nil), len(s))
+
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+
+		if (i & 1) == 1 {
+			h.Write(pSeq)
+		} else {
+			h.Write(sum)
+		}
+
+		if (i % 3) != 0 {
+			h.Write(sSeq)
+		}
+
+		if (i % 7) != 0 {
+			h.Write(pSeq)
+		}
+
+		if (i & 1) == 1 {
+			h.Write(sum)
+		} else {
+			h.Write(pSeq)
+		}
+
+		sum = h.Sum(sum[:0])
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString(pref)
+	if customRounds {
+		buf.WriteString("rounds=" + strconv.Itoa(rounds) + "$")
+	}
+	buf.WriteString(salt)
+	buf.WriteByte('$')
+
+	add := func(a, b, c byte, n int) {
+		v := (uint(a) << 16) + (uint(b) << 8) + uint(c)
+		for i := 0; i < n; i++ {
+			buf.WriteByte(aprCharacters[v&0x3f])
+			v >>= 6
+		}
+	}
+
+	for _, o := range order {
+		add(sum[o[0]], sum[o[1]], sum[o[2]], 4)
+	}
+
+	if pref == pwdPrefixSHA512 {
+		add(0, 0, sum[63], 2)
+	} else {
+		add(0, sum[31], sum[30], 3)
+	}
+
+	return buf.Bytes()
+}
+
+// writeRepeated writes the given bytes repeatedly to the hash until n bytes are written.
+func writeRepeated(h hash.Hash, b []byte, n int) {
+	for ; n > len(b); n -= len(b) {
+		h.Write(b)
+	}
+	h.Write(b[:n])
+}
+
+// repeatBytes returns n bytes of the given bytes repeated.
+func repeatBytes(b []byte, n int) []byte {
+	result := make([]byte, 0, n)
+	for ; n > len(b); n -= len(b) {
+		result = append(result, b...)
+	}
+	return append(result, b[:n]...)
+}
@@ -1,6 +1,9 @@
 package accesscontrol
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func Test_Apr1MD5(t *testing.T) {
 	var exp, res string
@@ -65,3 +68,61 @@ func Test_ValidateAccessData(t *testing.T) {
 		t.Error("Unexpected validation failure")
 	}
 }
+
+func Test_ShaCrypt(t *testing.T) {
+	for _, tc := range []struct {
+		pass, settings, pref, exp string
+	}{
+		// python3 -c 'import crypt; print(crypt.crypt("my-pass", "$5$saltstring"))'
+		{"my-pass", "saltstring", "$5$", "$5$saltstring$VhZ8qrFfOIMdZrqFIO8KmMsgO8Tv4Uq0p/VLxOqZzG2"},
+		{"my-pass", "rounds=10000$saltstringsaltstring", "$5$", "$5$rounds=10000$saltstringsaltst$eJiamnVorkEmCKrl5sRIsj/r0d83A.WT2Nlf0BC/ZJD"},
+		{"", "salt", "$5$", "$5$salt$HrcUzzoef72uxM/YhTU5BAi419Fblqlq//zyM.rIOG0"},
+		{"my-pass", "saltstring", "$6$", "$6$saltstring$4oaeTCeLcHQsTMeZCjUTSwPV2qcOm1HclDcK79VEVDSD0WvKjaJvfosDhlGjlAQ3uk.8vzEBLxzreASKxSm030"},
+		{"my-pass", "rounds=1000$short", "$6$", "$6$rounds=1000$short$yuyGAiPwtloWs1MhSKpildzsyzu74xTA8xvR6fP5S2/eOA8UvVt.pLvJeVe9hvDepi0zlK/XtkK9toxALvsYY1"},
+		{"Hello world!", "rounds=10000$saltstringsaltstring", "$6$", "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+		{strings.Repeat("a", 70), "longpass", "$6$", "$6$longpass$vT27umUGu44IKO8kmwLRMYUBCENoErauAMgbQhlRe2znwtWanVXcGbL6KViBbIK9I8gjE8wdJil5g2J5au9sX."},
+	} {
+		if res := string(shaCrypt(tc.pass, tc.settings, tc.pref)); res != tc.exp {
+			t.Errorf("Got unexpected password: '%s', want '%s'", res, tc.exp)
+		}
+	}
+}
+
+func Test_ParseArgon2id(t *testing.T) {
+	const saltHash = "$c29tZXNhbHQ$aGFzaA"
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"valid", "$argon2id$v=19$m=1024,t=2,p=1" + saltHash, ""},
+		{"format", "$argon2id$v=19$m=1024,t=2,p=1", "invalid format"},
+		{"version", "$argon2id$v=16$m=1024,t=2,p=1" + saltHash, `unsupported version: "v=16"`},
+		{"params", "$argon2id$v=19$m=1024" + saltHash, `invalid parameters: "m=1024"`},
+		{"time zero", "$argon2id$v=19$m=1024,t=0,p=1" + saltHash, "time must be between 1 and 100: 0"},
+		{"time max", "$argon2id$v=19$m=1024,t=101,p=1" + saltHash, "time must be between 1 and 100: 101"},
+		{"threads zero", "$argon2id$v=19$m=1024,t=2,p=0" + saltHash, "parallelism must be greater than 0"},
+		{"threads overflow", "$argon2id$v=19$m=1024,t=2,p=256" + saltHash, `invalid parameters: "m=1024,t=2,p=256"`},
+		{"memory min", "$argon2id$v=19$m=8,t=2,p=4" + saltHash, "memory must be between 32 and 1048576: 8"},
+		{"memory max", "$argon2id$v=19$m=4194304,t=2,p=1" + saltHash, "memory must be between 8 and 1048576: 4194304"},
+		{"salt", "$argon2id$v=19$m=1024,t=2,p=1$%$aGFzaA", "invalid salt: illegal base64 data at input byte 0"},
+		{"hash", "$argon2id$v=19$m=1024,t=2,p=1$c29tZXNhbHQ$", "invalid hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := parseArgon2id(tt.value)
+			if tt.wantErr == "" {
+				if err != nil {
+					subT.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				subT.Errorf("expected error %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
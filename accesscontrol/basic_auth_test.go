@@ -3,8 +3,12 @@ package accesscontrol_test
 import (
 	b64 "encoding/base64"
 	"errors"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	ac "github.com/avenga/couper/accesscontrol"
 )
@@ -27,10 +31,11 @@ func Test_NewBasicAuth(t *testing.T) {
 		{"name", "user", "pass", "testdata/htpasswd_err_invalid", "Basic", "basic auth ht parse error: invalidLine: testdata/htpasswd_err_invalid:1", true},
 		{"name", "user", "pass", "testdata/htpasswd_err_too_long", "Basic", "basic auth ht parse error: lineTooLong: testdata/htpasswd_err_too_long:1", true},
 		{"name", "user", "pass", "testdata/htpasswd_err_malformed", "Basic", `basic auth ht parse error: malformedPassword: testdata/htpasswd_err_malformed:1: user "foo"`, true},
+		{"name", "user", "pass", "testdata/htpasswd_err_malformed_argon2", "Basic", `basic auth ht parse error: malformedPassword: testdata/htpasswd_err_malformed_argon2:1: user "foo"`, true},
 		{"name", "user", "pass", "testdata/htpasswd_err_multi", "Basic", `basic auth ht parse error: multipleUser: testdata/htpasswd_err_multi:2: "foo"`, true},
 		{"name", "user", "pass", "testdata/htpasswd_err_unsupported", "Basic", "basic auth ht parse error: notSupported: testdata/htpasswd_err_unsupported:1: unknown password algorithm", true},
 	} {
-		ba, err := ac.NewBasicAuth(tc.name, tc.user, tc.pass, tc.file, tc.realm)
+		ba, err := ac.NewBasicAuth(tc.name, tc.user, tc.pass, tc.file, tc.realm, nil)
 		if tc.shouldFail && ba != nil {
 			t.Error("Expected no successful basic auth creation")
 		}
@@ -53,7 +58,7 @@ func Test_Validate(t *testing.T) {
 		t.Errorf("Expected NotConfigured error, got: %v", err)
 	}
 
-	ba, err := ac.NewBasicAuth("name", "user", "pass", "testdata/htpasswd", "Basic")
+	ba, err := ac.NewBasicAuth("name", "user", "pass", "testdata/htpasswd", "Basic", nil)
 	if err != nil || ba == nil {
 		t.Fatal("Expected a basic auth object")
 	}
@@ -95,7 +100,7 @@ func Test_ValidateEmptyUser(t *testing.T) {
 		t.Errorf("Expected NotConfigured error, got: %v", err)
 	}
 
-	ba, err := ac.NewBasicAuth("name", "", "pass", "", "Basic")
+	ba, err := ac.NewBasicAuth("name", "", "pass", "", "Basic", nil)
 	if err != nil || ba == nil {
 		t.Fatal("Expected a basic auth object")
 	}
@@ -131,7 +136,7 @@ func Test_ValidateEmptyPassword(t *testing.T) {
 		t.Errorf("Expected NotConfigured error, got: %v", err)
 	}
 
-	ba, err := ac.NewBasicAuth("name", "user", "", "", "Basic")
+	ba, err := ac.NewBasicAuth("name", "user", "", "", "Basic", nil)
 	if err != nil || ba == nil {
 		t.Fatal("Expected a basic auth object")
 	}
@@ -167,7 +172,7 @@ func Test_ValidateEmptyUserPassword(t *testing.T) {
 		t.Errorf("Expected NotConfigured error, got: %v", err)
 	}
 
-	ba, err := ac.NewBasicAuth("name", "", "", "", "Basic")
+	ba, err := ac.NewBasicAuth("name", "", "", "", "Basic", nil)
 	if err != nil || ba == nil {
 		t.Fatal("Expected a basic auth object")
 	}
@@ -194,3 +199,80 @@ func Test_ValidateEmptyUserPassword(t *testing.T) {
 		})
 	}
 }
+
+func Test_ValidateHTFile(t *testing.T) {
+	ba, err := ac.NewBasicAuth("name", "", "", "testdata/htpasswd", "Basic", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, user := range []string{"john", "jane", "sha", "sha256", "sha256rounds", "sha512", "argon2id"} {
+		t.Run(user, func(subT *testing.T) {
+			req := &http.Request{Header: make(http.Header)}
+
+			req.SetBasicAuth(user, "my-pass")
+			if err := ba.Validate(req); err != nil {
+				subT.Errorf("expected a valid password, got: %v", err)
+			}
+
+			req.SetBasicAuth(user, "my-bass")
+			if err := ba.Validate(req); err == nil {
+				subT.Error("expected an invalid password")
+			}
+		})
+	}
+}
+
+func Test_ReloadHTFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "basic_auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	htFile := filepath.Join(dir, "htpasswd")
+	writeHTFile := func(content string) {
+		if err := ioutil.WriteFile(htFile, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeHTFile("sha:{SHA}FcrlzJC8GZi2VJ8fE+4LjKSPmvc=\n")
+
+	ba, err := ac.NewBasicAuth("name", "", "", htFile, "Basic", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(user string) error {
+		req := &http.Request{Header: make(http.Header)}
+		req.SetBasicAuth(user, "my-pass")
+		return ba.Validate(req)
+	}
+
+	if err = validate("sha"); err != nil {
+		t.Fatalf("expected a valid user, got: %v", err)
+	}
+
+	reloadInterval := ac.FileReloadInterval
+	ac.FileReloadInterval = 0
+	defer func() { ac.FileReloadInterval = reloadInterval }()
+
+	// ensure a different modification time
+	time.Sleep(time.Millisecond * 10)
+	writeHTFile("sha512:$6$saltstring$4oaeTCeLcHQsTMeZCjUTSwPV2qcOm1HclDcK79VEVDSD0WvKjaJvfosDhlGjlAQ3uk.8vzEBLxzreASKxSm030\n")
+
+	if err = validate("sha"); err == nil {
+		t.Error("expected the removed user to be invalid")
+	}
+	if err = validate("sha512"); err != nil {
+		t.Errorf("expected the added user to be valid, got: %v", err)
+	}
+
+	// invalid content keeps the current users
+	time.Sleep(time.Millisecond * 10)
+	writeHTFile("sha512:unsupported\n")
+
+	if err = validate("sha512"); err != nil {
+		t.Errorf("expected the current users after an invalid reload, got: %v", err)
+	}
+}
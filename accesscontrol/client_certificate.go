@@ -0,0 +1,246 @@
+package accesscontrol
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var _ AccessControl = &ClientCertificate{}
+
+var (
+	ErrorClientCertificateConstraint    = errors.New("client certificate does not match the configured constraints")
+	ErrorClientCertificateCRLExpired    = errors.New("crl is expired")
+	ErrorClientCertificateCRLNotValid   = errors.New("crl is not signed by a configured ca certificate")
+	ErrorClientCertificateInvalid       = errors.New("client certificate is invalid")
+	ErrorClientCertificateMissing       = errors.New("missing client certificate")
+	ErrorClientCertificateMissingCA     = errors.New("no ca certificate found in ca_file")
+	ErrorClientCertificateNotConfigured = errors.New("client certificate handler not configured")
+	ErrorClientCertificateRevoked       = errors.New("client certificate is revoked")
+)
+
+// ClientCertificate represents an AC-ClientCertificate object which validates
+// the certificate presented by the client during the TLS handshake.
+type ClientCertificate struct {
+	caCerts     []*x509.Certificate
+	commonNames []string
+	dnsNames    []string
+	emails      []string
+	name        string
+	pool        *x509.CertPool
+	revocations map[[sha256.Size]byte]*revocationList
+}
+
+// revocationList holds the revoked serial numbers of a ca certificate.
+type revocationList struct {
+	nextUpdate time.Time
+	serials    map[string]bool
+}
+
+// NewClientCertificate parses the PEM encoded CA bundle and the optional revocation list
+// and creates a ClientCertificate object which can be referenced in related handlers.
+func NewClientCertificate(name string, caBundle, crl []byte, commonNames, dnsNames, emails []string) (*ClientCertificate, error) {
+	cc := &ClientCertificate{
+		commonNames: commonNames,
+		dnsNames:    dnsNames,
+		emails:      emails,
+		name:        name,
+		pool:        x509.NewCertPool(),
+		revocations: make(map[[sha256.Size]byte]*revocationList),
+	}
+
+	rest := caBundle
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		cc.caCerts = append(cc.caCerts, cert)
+		cc.pool.AddCert(cert)
+	}
+
+	if len(cc.caCerts) == 0 {
+		return nil, ErrorClientCertificateMissingCA
+	}
+
+	for _, der := range decodeCRLs(crl) {
+		if err := cc.addCRL(der); err != nil {
+			return nil, err
+		}
+	}
+
+	return cc, nil
+}
+
+// decodeCRLs returns the DER encoded revocation lists of the given PEM or DER content.
+func decodeCRLs(content []byte) [][]byte {
+	if len(content) == 0 {
+		return nil
+	}
+
+	var crls [][]byte
+	rest := content
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "X509 CRL" {
+			crls = append(crls, block.Bytes)
+		}
+	}
+
+	if len(crls) == 0 { // DER
+		crls = append(crls, content)
+	}
+	return crls
+}
+
+// addCRL verifies the revocation list against its issuing ca certificate
+// and records the revoked serial numbers of this issuer.
+func (cc *ClientCertificate) addCRL(der []byte) error {
+	crl, err := x509.ParseRevocationList(der)
+	if err != nil {
+		return err
+	}
+
+	var issuer *x509.Certificate
+	for _, ca := range cc.caCerts {
+		if crl.CheckSignatureFrom(ca) == nil {
+			issuer = ca
+			break
+		}
+	}
+	if issuer == nil {
+		return ErrorClientCertificateCRLNotValid
+	}
+
+	if !crl.NextUpdate.IsZero() && time.Now().After(crl.NextUpdate) {
+		return ErrorClientCertificateCRLExpired
+	}
+
+	list := &revocationList{nextUpdate: crl.NextUpdate, serials: make(map[string]bool)}
+	for _, revoked := range crl.RevokedCertificateEntries {
+		list.serials[revoked.SerialNumber.String()] = true
+	}
+	cc.revocations[sha256.Sum256(issuer.Raw)] = list
+	return nil
+}
+
+// checkRevocation checks each certificate of the chain against the revocation list of its issuer.
+// Certificates of an issuer with an expired revocation list are rejected.
+func (cc *ClientCertificate) checkRevocation(chain []*x509.Certificate) error {
+	for i := 0; i < len(chain)-1; i++ {
+		list, exist := cc.revocations[sha256.Sum256(chain[i+1].Raw)]
+		if !exist {
+			continue
+		}
+
+		if !list.nextUpdate.IsZero() && time.Now().After(list.nextUpdate) {
+			return ErrorClientCertificateCRLExpired
+		}
+
+		if list.serials[chain[i].SerialNumber.String()] {
+			return ErrorClientCertificateRevoked
+		}
+	}
+	return nil
+}
+
+// Validate implements the AccessControl interface.
+func (cc *ClientCertificate) Validate(req *http.Request) error {
+	if cc == nil {
+		return ErrorClientCertificateNotConfigured
+	}
+
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return ErrorClientCertificateMissing
+	}
+
+	cert := req.TLS.PeerCertificates[0]
+
+	intermediates := x509.NewCertPool()
+	for _, c := range req.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+
+	chains, err := cert.Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		Roots:         cc.pool,
+	})
+	if err != nil || len(chains) == 0 {
+		return ErrorClientCertificateInvalid
+	}
+
+	if err = cc.checkRevocation(chains[0]); err != nil {
+		return err
+	}
+
+	if !cc.matchConstraints(cert) {
+		return ErrorClientCertificateConstraint
+	}
+
+	setAccessControlContext(req, cc.name, certificateFields(cert))
+
+	return nil
+}
+
+// matchConstraints checks every configured constraint type, at least
+// one value of each configured type must match the certificate.
+func (cc *ClientCertificate) matchConstraints(cert *x509.Certificate) bool {
+	if len(cc.commonNames) > 0 && !containsAny(cc.commonNames, cert.Subject.CommonName) {
+		return false
+	}
+
+	if len(cc.dnsNames) > 0 && !containsAny(cc.dnsNames, cert.DNSNames...) {
+		return false
+	}
+
+	if len(cc.emails) > 0 && !containsAny(cc.emails, cert.EmailAddresses...) {
+		return false
+	}
+
+	return true
+}
+
+func certificateFields(cert *x509.Certificate) map[string]interface{} {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	return map[string]interface{}{
+		"common_name":        cert.Subject.CommonName,
+		"dns_names":          cert.DNSNames,
+		"email_addresses":    cert.EmailAddresses,
+		"fingerprint_sha256": hex.EncodeToString(fingerprint[:]),
+		"issuer":             cert.Issuer.String(),
+		"not_after":          float64(cert.NotAfter.Unix()),
+		"not_before":         float64(cert.NotBefore.Unix()),
+		"serial_number":      cert.SerialNumber.String(),
+		"subject":            cert.Subject.String(),
+	}
+}
+
+func containsAny(list []string, values ...string) bool {
+	for _, v := range values {
+		for _, l := range list {
+			if l == v {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package accesscontrol_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/internal/test"
+)
+
+func TestClientCertificate_Validate(t *testing.T) {
+	helper := test.New(t)
+
+	ca, caKey, caPEM := newTestCertificate(t, "ca", 1, nil, nil, true)
+	otherCA, otherCAKey, otherCAPEM := newTestCertificate(t, "other-ca", 2, nil, nil, true)
+	bundlePEM := append(append([]byte{}, caPEM...), otherCAPEM...)
+
+	client, _, _ := newTestCertificate(t, "client", 10, ca, caKey, false)
+	revoked, _, _ := newTestCertificate(t, "revoked", 11, ca, caKey, false)
+	foreign, _, _ := newTestCertificate(t, "foreign", 12, nil, nil, false)
+	// same serial number as the revoked one, but another issuer
+	otherClient, _, _ := newTestCertificate(t, "client", 11, otherCA, otherCAKey, false)
+
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:              big.NewInt(1),
+		RevokedCertificates: []pkix.RevokedCertificate{{SerialNumber: revoked.SerialNumber, RevocationTime: time.Now()}},
+		ThisUpdate:          time.Now(),
+		NextUpdate:          time.Now().Add(time.Hour),
+	}, ca, caKey)
+	helper.Must(err)
+
+	newReq := func(certs ...*x509.Certificate) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if len(certs) > 0 {
+			req.TLS = &tls.ConnectionState{PeerCertificates: certs}
+		}
+		return req
+	}
+
+	tests := []struct {
+		name        string
+		caPEM       []byte
+		commonNames []string
+		req         *http.Request
+		wantErr     error
+	}{
+		{"no tls", caPEM, nil, newReq(), ac.ErrorClientCertificateMissing},
+		{"valid", caPEM, nil, newReq(client), nil},
+		{"valid /w common name", caPEM, []string{"other", "client"}, newReq(client), nil},
+		{"common name mismatch", caPEM, []string{"other"}, newReq(client), ac.ErrorClientCertificateConstraint},
+		{"unknown ca", caPEM, nil, newReq(foreign), ac.ErrorClientCertificateInvalid},
+		{"other ca", otherCAPEM, nil, newReq(client), ac.ErrorClientCertificateInvalid},
+		{"revoked", caPEM, nil, newReq(revoked), ac.ErrorClientCertificateRevoked},
+		{"revoked /w bundle", bundlePEM, nil, newReq(revoked), ac.ErrorClientCertificateRevoked},
+		{"other issuer serial", bundlePEM, nil, newReq(otherClient), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			var crlBytes []byte
+			if string(tt.caPEM) != string(otherCAPEM) {
+				crlBytes = crl
+			}
+
+			cc, err := ac.NewClientCertificate("mtls", tt.caPEM, crlBytes, tt.commonNames, nil, nil)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			err = cc.Validate(tt.req)
+			if err != tt.wantErr {
+				subT.Errorf("Expected error %v, got: %v", tt.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			acMap, ok := tt.req.Context().Value(request.AccessControls).(map[string]interface{})
+			if !ok {
+				subT.Fatal("Expected access control context")
+			}
+			fields, _ := acMap["mtls"].(map[string]interface{})
+			if fields["common_name"] != "client" {
+				subT.Errorf("Expected common_name context field, got: %v", fields["common_name"])
+			}
+		})
+	}
+}
+
+func TestNewClientCertificate_Errors(t *testing.T) {
+	if _, err := ac.NewClientCertificate("mtls", []byte("no pem"), nil, nil, nil, nil); err != ac.ErrorClientCertificateMissingCA {
+		t.Errorf("Expected missing ca error, got: %v", err)
+	}
+
+	_, _, caPEM := newTestCertificate(t, "ca", 1, nil, nil, true)
+	other, otherKey, _ := newTestCertificate(t, "other-ca", 2, nil, nil, true)
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now(),
+		NextUpdate: time.Now().Add(time.Hour),
+	}, other, otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ac.NewClientCertificate("mtls", caPEM, crl, nil, nil, nil); err != ac.ErrorClientCertificateCRLNotValid {
+		t.Errorf("Expected crl signature error, got: %v", err)
+	}
+
+	expiredCRL, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(-time.Minute),
+	}, other, otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: other.Raw})
+	if _, err = ac.NewClientCertificate("mtls", otherPEM, expiredCRL, nil, nil, nil); err != ac.ErrorClientCertificateCRLExpired {
+		t.Errorf("Expected crl expired error, got: %v", err)
+	}
+}
+
+func TestClientCertificate_ExpiringCRL(t *testing.T) {
+	ca, caKey, caPEM := newTestCertificate(t, "ca", 1, nil, nil, true)
+	client, _, _ := newTestCertificate(t, "client", 10, ca, caKey, false)
+
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now(),
+		// encoded with second precision
+		NextUpdate: time.Now().Add(time.Millisecond * 1500),
+	}, ca, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crlPEM := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl})
+	cc, err := ac.NewClientCertificate("mtls", caPEM, crlPEM, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{client}}
+	if err = cc.Validate(req); err != nil {
+		t.Fatalf("Expected a valid certificate, got: %v", err)
+	}
+
+	time.Sleep(time.Millisecond * 1600)
+
+	if err = cc.Validate(req); err != ac.ErrorClientCertificateCRLExpired {
+		t.Errorf("Expected crl expired error, got: %v", err)
+	}
+}
+
+func newTestCertificate(t *testing.T, cn string, serial int64, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		tpl.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+
+	signer, signerKey := tpl, key
+	if parent != nil {
+		signer, signerKey = parent, parentKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
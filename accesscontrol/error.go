@@ -0,0 +1,111 @@
+package accesscontrol
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	couperErr "github.com/avenga/couper/errors"
+)
+
+// BearerError describes a failed bearer token validation which gets responded
+// with a "WWW-Authenticate: Bearer" challenge, see RFC 6750.
+type BearerError struct {
+	error
+	Realm string
+}
+
+func (e *BearerError) Unwrap() error {
+	return e.error
+}
+
+// Challenge returns the WWW-Authenticate header value. Requests without a token
+// get no error code, see RFC 6750 section 3.1.
+func (e *BearerError) Challenge() string {
+	var params []string
+	if e.Realm != "" {
+		params = append(params, fmt.Sprintf("realm=%q", e.Realm))
+	}
+
+	switch {
+	case errors.Is(e.error, ErrorEmptyToken):
+	case errors.Is(e.error, ErrorBearerRequired):
+		params = append(params, `error="invalid_request"`, fmt.Sprintf("error_description=%q", e.description()))
+	default:
+		params = append(params, `error="invalid_token"`, fmt.Sprintf("error_description=%q", e.description()))
+	}
+
+	if len(params) == 0 {
+		return "Bearer"
+	}
+	return "Bearer " + strings.Join(params, ", ")
+}
+
+// description returns a fixed error_description. The details of the error, e.g. parser
+// messages or failed upstream requests, are logged only.
+func (e *BearerError) description() string {
+	if errors.Is(e.error, ErrorBearerRequired) {
+		return e.error.Error()
+	}
+
+	if errors.Is(e.error, ErrorTokenInactive) {
+		return "token is not active"
+	}
+
+	code, _ := TokenErrorCode(e.error)
+	switch code {
+	case couperErr.TokenExpired:
+		return "token is expired"
+	case couperErr.TokenSignatureInvalid:
+		return "token signature is invalid"
+	case couperErr.TokenIssuerInvalid:
+		return "token issuer is invalid"
+	case couperErr.TokenClaimMissing:
+		var claimErr *ClaimError
+		errors.As(e.error, &claimErr)
+		return fmt.Sprintf("token claim %q is missing", claimErr.Claim)
+	default:
+		return "token is invalid"
+	}
+}
+
+// NamedError describes the failure of a named access control with its optional error template.
+type NamedError struct {
+	error
+	Name     string
+	Template *couperErr.Template
+}
+
+func (e *NamedError) Unwrap() error {
+	return e.error
+}
+
+// TokenErrorCode returns the distinct error code of expired, wrongly signed or issued
+// tokens and tokens with missing claims.
+func TokenErrorCode(err error) (couperErr.Code, bool) {
+	var (
+		claimErr     *ClaimError
+		expiredErr   *jwt.TokenExpiredError
+		issuerErr    *jwt.InvalidIssuerError
+		signatureErr *jwt.InvalidSignatureError
+	)
+
+	switch {
+	case errors.As(err, &expiredErr):
+		return couperErr.TokenExpired, true
+	case errors.As(err, &signatureErr):
+		return couperErr.TokenSignatureInvalid, true
+	case errors.As(err, &issuerErr):
+		return couperErr.TokenIssuerInvalid, true
+	case errors.As(err, &claimErr):
+		if claimErr.Claim == "iss" {
+			return couperErr.TokenIssuerInvalid, true
+		}
+		if claimErr.Missing {
+			return couperErr.TokenClaimMissing, true
+		}
+	}
+	return 0, false
+}
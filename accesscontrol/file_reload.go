@@ -0,0 +1,98 @@
+package accesscontrol
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FileReloadInterval defines the interval for file change lookups of reloadable files.
+var FileReloadInterval = time.Second * 2
+
+// reloadableFile provides the parsed content of a file which is reloaded on changes.
+// Changes are looked up on access, at most once per <FileReloadInterval>. A failing
+// reload keeps the current content.
+type reloadableFile struct {
+	log   *logrus.Entry
+	parse func([]byte) (interface{}, error)
+	path  string
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	modTime   time.Time
+	size      int64
+	value     interface{}
+}
+
+func newReloadableFile(path string, parse func([]byte) (interface{}, error), log *logrus.Entry) (*reloadableFile, error) {
+	f := &reloadableFile{log: log, parse: parse, path: path}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// get returns the current content and triggers a reload if the file has been changed.
+func (f *reloadableFile) get() interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if time.Since(f.checkedAt) < FileReloadInterval {
+		return f.value
+	}
+	f.checkedAt = time.Now()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		f.logError(err)
+		return f.value
+	}
+
+	if info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.value
+	}
+
+	if err = f.load(); err != nil {
+		f.logError(err)
+		// prevent repetitive reloads of the invalid content
+		f.modTime, f.size = info.ModTime(), info.Size()
+	}
+	return f.value
+}
+
+func (f *reloadableFile) load() error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	value, err := f.parse(content)
+	if err != nil {
+		return err
+	}
+
+	f.checkedAt = time.Now()
+	f.modTime, f.size = info.ModTime(), info.Size()
+	f.value = value
+	return nil
+}
+
+func (f *reloadableFile) logError(err error) {
+	if f.log != nil {
+		f.log.WithError(err).Errorf("reloading file failed, keeping the current content: %s", f.path)
+	}
+}
@@ -0,0 +1,121 @@
+package accesscontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/avenga/couper/config/request"
+	couperErr "github.com/avenga/couper/errors"
+)
+
+var (
+	_ AccessControl = &Group{}
+	_ AccessControl = &Named{}
+)
+
+// GroupMode defines how a <Group> combines the results of its access controls.
+type GroupMode uint8
+
+const (
+	AllOf GroupMode = iota
+	AnyOf
+)
+
+// Group combines access controls of which all or any of them must grant access.
+// Groups may contain other groups.
+type Group struct {
+	controls List
+	mode     GroupMode
+}
+
+// NewGroup creates a new <*Group> object.
+func NewGroup(mode GroupMode, controls ...AccessControl) (*Group, error) {
+	if len(controls) == 0 {
+		return nil, fmt.Errorf("access control group: at least one access control is required")
+	}
+	return &Group{controls: controls, mode: mode}, nil
+}
+
+// Validate implements the <AccessControl> interface. All-of groups fail with the first
+// error, any-of groups with the most relevant error of all their access controls.
+func (g *Group) Validate(req *http.Request) error {
+	if g.mode == AllOf {
+		for _, control := range g.controls {
+			if err := control.Validate(req); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var result error
+	for _, control := range g.controls {
+		// restore the request of partially granted access, e.g. context values of nested
+		// all-of groups or a token stripped from the url or body
+		saved, savedURL, savedHeader := *req, *req.URL, req.Header.Clone()
+		err := control.Validate(req)
+		if err == nil {
+			return nil
+		}
+		*req = saved
+		*req.URL = savedURL
+		req.Header = savedHeader
+
+		if result == nil || errorRelevance(err) > errorRelevance(result) {
+			result = err
+		}
+	}
+	return result
+}
+
+// errorRelevance ranks access control errors: rejected credentials are more relevant
+// than a login redirect which is more relevant than missing credentials.
+func errorRelevance(err error) int {
+	var redirect *RedirectError
+	if errors.As(err, &redirect) {
+		return 1
+	}
+
+	for _, missing := range []error{
+		ErrorBasicAuthMissingCredentials, ErrorClientCertificateMissing, ErrorEmptyToken, ErrorOIDCSessionRequired,
+	} {
+		if errors.Is(err, missing) {
+			return 0
+		}
+	}
+	return 2
+}
+
+// Named records the name of the given access control within the request context once
+// it grants access. Failures are returned as <*NamedError> with the optional error template.
+type Named struct {
+	control  AccessControl
+	errorTpl *couperErr.Template
+	name     string
+}
+
+// NewNamed creates a new <*Named> object.
+func NewNamed(name string, control AccessControl, errorTpl *couperErr.Template) *Named {
+	return &Named{control: control, errorTpl: errorTpl, name: name}
+}
+
+// Validate implements the <AccessControl> interface.
+func (n *Named) Validate(req *http.Request) error {
+	if err := n.control.Validate(req); err != nil {
+		return &NamedError{error: err, Name: n.name, Template: n.errorTpl}
+	}
+
+	granted, _ := req.Context().Value(request.AccessControlsGranted).([]string)
+	for _, name := range granted {
+		if name == n.name {
+			return nil
+		}
+	}
+
+	names := make([]string, 0, len(granted)+1)
+	names = append(append(names, granted...), n.name)
+	*req = *req.WithContext(context.WithValue(req.Context(), request.AccessControlsGranted, names))
+	return nil
+}
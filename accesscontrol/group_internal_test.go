@@ -0,0 +1,47 @@
+package accesscontrol
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/avenga/couper/config/request"
+)
+
+func TestGroup_Validate_AccessControlContext(t *testing.T) {
+	withContext := func(name string, err error) AccessControl {
+		return NewNamed(name, ValidateFunc(func(req *http.Request) error {
+			setAccessControlContext(req, name, map[string]interface{}{"sub": name})
+			return err
+		}), nil)
+	}
+
+	outer := withContext("jwtOuter", nil)
+	partial, err := NewGroup(AllOf, withContext("jwtA", nil), withContext("failing", errors.New("invalid")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group, err := NewGroup(AnyOf, partial, withContext("other", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, control := range []AccessControl{outer, group} {
+		if err = control.Validate(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+	expected := map[string]interface{}{
+		"jwtOuter": map[string]interface{}{"sub": "jwtOuter"},
+		"other":    map[string]interface{}{"sub": "other"},
+	}
+	if !reflect.DeepEqual(acMap, expected) {
+		t.Errorf("expected access control context %v, got: %v", expected, acMap)
+	}
+}
@@ -0,0 +1,134 @@
+package accesscontrol_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config/request"
+)
+
+func TestGroup_Validate(t *testing.T) {
+	errInvalid := errors.New("invalid")
+
+	named := func(name string, err error) ac.AccessControl {
+		return ac.NewNamed(name, ac.ValidateFunc(func(_ *http.Request) error { return err }), nil)
+	}
+
+	newGroup := func(mode ac.GroupMode, controls ...ac.AccessControl) ac.AccessControl {
+		group, err := ac.NewGroup(mode, controls...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return group
+	}
+
+	redirect := &ac.RedirectError{Location: "/login"}
+
+	tests := []struct {
+		name        string
+		control     ac.AccessControl
+		wantErr     error
+		wantGranted []string
+	}{
+		{"all granted", newGroup(ac.AllOf, named("a", nil), named("b", nil)), nil, []string{"a", "b"}},
+		{"all first error", newGroup(ac.AllOf, named("a", ac.ErrorEmptyToken), named("b", errInvalid)), ac.ErrorEmptyToken, nil},
+		{"any granted", newGroup(ac.AnyOf, named("a", ac.ErrorEmptyToken), named("b", nil), named("c", nil)), nil, []string{"b"}},
+		{"any invalid over missing", newGroup(ac.AnyOf, named("a", ac.ErrorEmptyToken), named("b", errInvalid)), errInvalid, nil},
+		{"any redirect over missing", newGroup(ac.AnyOf, named("a", ac.ErrorEmptyToken), named("b", redirect)), redirect, nil},
+		{"any invalid over redirect", newGroup(ac.AnyOf, named("a", redirect), named("b", errInvalid)), errInvalid, nil},
+		{"any first missing", newGroup(ac.AnyOf, named("a", ac.ErrorEmptyToken), named("b", ac.ErrorOIDCSessionRequired)), ac.ErrorEmptyToken, nil},
+		{"nested partial grant", newGroup(ac.AnyOf,
+			newGroup(ac.AllOf, named("a", nil), named("b", errInvalid)),
+			newGroup(ac.AllOf, named("c", nil), named("a", nil)),
+		), nil, []string{"c", "a"}},
+		{"duplicate grant", newGroup(ac.AllOf, named("a", nil), named("a", nil)), nil, []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if err := tt.control.Validate(req); !errors.Is(err, tt.wantErr) {
+				subT.Fatalf("expected error %v, got: %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			granted, _ := req.Context().Value(request.AccessControlsGranted).([]string)
+			if !reflect.DeepEqual(granted, tt.wantGranted) {
+				subT.Errorf("expected granted access controls %v, got: %v", tt.wantGranted, granted)
+			}
+		})
+	}
+
+	if _, err := ac.NewGroup(ac.AnyOf); err == nil {
+		t.Error("expected an error for an empty group")
+	}
+}
+
+func TestGroup_Validate_StripToken(t *testing.T) {
+	key := []byte("mySecretK3y")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "me"}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newJWT := func(source ac.Source, strip bool) ac.AccessControl {
+		j, jerr := ac.NewJWT("HS256", "token", nil, nil, source, "token", key)
+		if jerr != nil {
+			t.Fatal(jerr)
+		}
+		if strip {
+			j.StripToken()
+		}
+		return ac.ValidateFunc(j.Validate)
+	}
+
+	failing := ac.ValidateFunc(func(_ *http.Request) error { return ac.ErrorBasicAuthMissingCredentials })
+
+	for _, tc := range []struct {
+		name   string
+		source ac.Source
+		req    func() *http.Request
+	}{
+		{"query", ac.QueryParam, func() *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/?a=b&token="+token, nil)
+		}},
+		{"post", ac.PostParam, func() *http.Request {
+			body := "c=d&token=" + token
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(strings.NewReader(body)), nil
+			}
+			return req
+		}},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			// the failing all-of branch strips the token before the second branch validates it
+			stripping, gerr := ac.NewGroup(ac.AllOf, newJWT(tc.source, true), failing)
+			if gerr != nil {
+				subT.Fatal(gerr)
+			}
+			group, gerr := ac.NewGroup(ac.AnyOf, stripping, newJWT(tc.source, false))
+			if gerr != nil {
+				subT.Fatal(gerr)
+			}
+
+			req := tc.req()
+			if verr := group.Validate(req); verr != nil {
+				subT.Errorf("expected the token for the second branch, got: %v", verr)
+			}
+		})
+	}
+}
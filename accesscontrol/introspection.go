@@ -0,0 +1,275 @@
+package accesscontrol
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/eval"
+)
+
+const (
+	// DefaultIntrospectionTTL is the maximum lifetime of cached introspection results.
+	DefaultIntrospectionTTL = time.Minute
+
+	introspectionCacheSize = 10000
+	introspectionTimeout   = time.Minute
+)
+
+var (
+	ErrorTokenInactive = errors.New("introspection: token is not active")
+
+	_ AccessControl = &Introspection{}
+)
+
+// IntrospectionOptions represents the configuration of an <Introspection> access control.
+type IntrospectionOptions struct {
+	AllowedClientIDs []string
+	Backend          http.RoundTripper
+	ClientID         string
+	ClientSecret     string
+	Endpoint         string
+	Header           string
+	Name             string
+	Realm            string
+	TTL              time.Duration
+}
+
+// Introspection validates opaque tokens with an OAuth2 token introspection endpoint (RFC 7662).
+// Positive and negative results are cached for the configured TTL, active tokens at most
+// until their expiry. The least recently used results are dropped once the cache is full.
+type Introspection struct {
+	options *IntrospectionOptions
+
+	mu         sync.Mutex
+	cache      map[[sha256.Size]byte]*list.Element
+	inflight   map[[sha256.Size]byte]*introspectionCall
+	lru        *list.List
+	maxEntries int
+}
+
+type introspectionResult struct {
+	data      map[string]interface{}
+	expiresAt time.Time
+	key       [sha256.Size]byte
+}
+
+// introspectionCall is a running introspection request, concurrent
+// lookups of the same token wait for its result.
+type introspectionCall struct {
+	data map[string]interface{}
+	done chan struct{}
+	err  error
+}
+
+// NewIntrospection creates a new <*Introspection> object.
+func NewIntrospection(opts *IntrospectionOptions) (*Introspection, error) {
+	if opts.Backend == nil {
+		return nil, fmt.Errorf("introspection: missing backend")
+	}
+
+	if _, err := url.ParseRequestURI(opts.Endpoint); err != nil {
+		return nil, fmt.Errorf("introspection: invalid endpoint: %v", err)
+	}
+
+	if opts.Header == "" {
+		opts.Header = "Authorization"
+	}
+
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultIntrospectionTTL
+	}
+
+	return &Introspection{
+		cache:      make(map[[sha256.Size]byte]*list.Element),
+		inflight:   make(map[[sha256.Size]byte]*introspectionCall),
+		lru:        list.New(),
+		maxEntries: introspectionCacheSize,
+		options:    opts,
+	}, nil
+}
+
+// Validate implements the <AccessControl> interface. Failures are returned as <*BearerError>.
+func (i *Introspection) Validate(req *http.Request) error {
+	if err := i.validate(req); err != nil {
+		return &BearerError{error: err, Realm: i.options.Realm}
+	}
+	return nil
+}
+
+func (i *Introspection) validate(req *http.Request) error {
+	tokenValue := req.Header.Get(i.options.Header)
+	if tokenValue == "" {
+		return ErrorEmptyToken
+	}
+
+	if i.options.Header == "Authorization" {
+		var err error
+		if tokenValue, err = getBearer(tokenValue); err != nil {
+			return err
+		}
+	}
+
+	data, err := i.introspect(req.Context(), tokenValue)
+	if err != nil {
+		return err
+	}
+
+	if active, _ := data["active"].(bool); !active {
+		return ErrorTokenInactive
+	}
+
+	if exp, ok := data["exp"].(float64); ok && time.Now().Unix() >= int64(exp) {
+		return ErrorTokenInactive
+	}
+
+	if len(i.options.AllowedClientIDs) > 0 {
+		clientID, _ := data["client_id"].(string)
+		if !containsString(i.options.AllowedClientIDs, clientID) {
+			return &ClaimError{Claim: "client_id", Message: "unexpected value"}
+		}
+	}
+
+	setAccessControlContext(req, i.options.Name, data)
+
+	if scope, ok := data["scope"]; ok {
+		grantScopes(req, scope)
+	}
+
+	return nil
+}
+
+// introspect returns the cached or requested introspection response of the given token.
+func (i *Introspection) introspect(ctx context.Context, token string) (map[string]interface{}, error) {
+	key := sha256.Sum256([]byte(token))
+
+	i.mu.Lock()
+	if elem, exist := i.cache[key]; exist {
+		result := elem.Value.(*introspectionResult)
+		if time.Now().Before(result.expiresAt) {
+			i.lru.MoveToFront(elem)
+			i.mu.Unlock()
+			return result.data, nil
+		}
+		i.lru.Remove(elem)
+		delete(i.cache, key)
+	}
+
+	call, exist := i.inflight[key]
+	if !exist {
+		call = &introspectionCall{done: make(chan struct{})}
+		i.inflight[key] = call
+		go i.run(ctx, key, call, token)
+	}
+	i.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.data, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run requests the introspection response for all callers waiting for the given call.
+func (i *Introspection) run(ctx context.Context, key [sha256.Size]byte, call *introspectionCall, token string) {
+	ctx, cancel := detachContext(ctx)
+	defer cancel()
+
+	call.data, call.err = i.request(ctx, token)
+
+	i.mu.Lock()
+	delete(i.inflight, key)
+	if call.err == nil {
+		i.store(key, call.data)
+	}
+	i.mu.Unlock()
+	close(call.done)
+}
+
+// detachContext returns a context for the shared introspection request which cannot be
+// canceled by the client request of the first caller. Only the values for the backend
+// evaluation and the upstream log are taken over.
+func detachContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.Background()
+	for _, key := range []interface{}{eval.ContextType, request.ServerName, request.UID} {
+		if value := ctx.Value(key); value != nil {
+			detached = context.WithValue(detached, key, value)
+		}
+	}
+	return context.WithTimeout(detached, introspectionTimeout)
+}
+
+// store caches the given introspection response and drops the least recently used
+// one if the cache is full. Must be called with the lock held.
+func (i *Introspection) store(key [sha256.Size]byte, data map[string]interface{}) {
+	expiresAt := time.Now().Add(i.options.TTL)
+	if exp, ok := data["exp"].(float64); ok {
+		if tokenExpiry := time.Unix(int64(exp), 0); tokenExpiry.Before(expiresAt) {
+			expiresAt = tokenExpiry
+		}
+	}
+
+	if i.lru.Len() >= i.maxEntries {
+		oldest := i.lru.Back()
+		i.lru.Remove(oldest)
+		delete(i.cache, oldest.Value.(*introspectionResult).key)
+	}
+
+	i.cache[key] = i.lru.PushFront(&introspectionResult{data: data, expiresAt: expiresAt, key: key})
+}
+
+func (i *Introspection) request(ctx context.Context, token string) (map[string]interface{}, error) {
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", "access_token")
+
+	outCtx := context.WithValue(ctx, request.RoundTripName, "introspection")
+	req, err := http.NewRequestWithContext(outCtx, http.MethodPost, i.options.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if i.options.ClientID != "" {
+		// RFC 6749, section 2.3.1
+		req.SetBasicAuth(url.QueryEscape(i.options.ClientID), url.QueryEscape(i.options.ClientSecret))
+	}
+
+	res, err := i.options.Backend.RoundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection: %v", err)
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("introspection: %v", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection: unexpected status: %d", res.StatusCode)
+	}
+
+	data := make(map[string]interface{})
+	if err = json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("introspection: invalid response: %v", err)
+	}
+
+	if _, ok := data["active"].(bool); !ok {
+		return nil, fmt.Errorf("introspection: invalid response: missing active field")
+	}
+	return data, nil
+}
@@ -0,0 +1,50 @@
+package accesscontrol
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIntrospection_CacheEviction(t *testing.T) {
+	var requests int32
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`{"active": true}`))
+	}))
+	defer origin.Close()
+
+	introspection, err := NewIntrospection(&IntrospectionOptions{
+		Backend:  http.DefaultTransport,
+		Endpoint: origin.URL + "/introspect",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	introspection.maxEntries = 2
+
+	for i, step := range []struct {
+		token        string
+		wantRequests int32
+	}{
+		{"a", 1},
+		{"b", 1},
+		{"a", 0},
+		// drops the least recently used b
+		{"c", 1},
+		{"a", 0},
+		{"b", 1},
+	} {
+		before := atomic.LoadInt32(&requests)
+		if _, err = introspection.introspect(context.Background(), step.token); err != nil {
+			t.Fatal(err)
+		}
+
+		if n := atomic.LoadInt32(&requests) - before; n != step.wantRequests {
+			t.Errorf("step %d: token %q: expected %d requests, got: %d", i, step.token, step.wantRequests, n)
+		}
+	}
+}
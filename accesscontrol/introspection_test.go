@@ -0,0 +1,191 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config/request"
+)
+
+func TestIntrospection_Validate(t *testing.T) {
+	var requests int32
+	responses := map[string]map[string]interface{}{
+		"valid":     {"active": true, "client_id": "app", "scope": "read write", "sub": "john"},
+		"inactive":  {"active": false},
+		"expired":   {"active": true, "client_id": "app", "exp": time.Now().Add(-time.Minute).Unix()},
+		"other_app": {"active": true, "client_id": "other"},
+	}
+
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if id, secret, _ := req.BasicAuth(); id != "couper" || secret != "s3cr3t" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = req.ParseForm()
+		if req.PostForm.Get("token") == "error" {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(responses[req.PostForm.Get("token")])
+	}))
+	defer origin.Close()
+
+	introspection, err := ac.NewIntrospection(&ac.IntrospectionOptions{
+		AllowedClientIDs: []string{"app"},
+		Backend:          http.DefaultTransport,
+		ClientID:         "couper",
+		ClientSecret:     "s3cr3t",
+		Endpoint:         origin.URL + "/introspect",
+		Name:             "opaque",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		wantErr      bool
+		wantRequests int32
+	}{
+		{"valid", "valid", false, 1},
+		{"cached valid", "valid", false, 0},
+		{"inactive", "inactive", true, 1},
+		{"cached inactive", "inactive", true, 0},
+		{"expired", "expired", true, 1},
+		{"client_id", "other_app", true, 1},
+		{"endpoint error", "error", true, 1},
+		{"endpoint error not cached", "error", true, 1},
+		{"missing token", "", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			before := atomic.LoadInt32(&requests)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			err := introspection.Validate(req)
+			if (err != nil) != tt.wantErr {
+				subT.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if n := atomic.LoadInt32(&requests) - before; n != tt.wantRequests {
+				subT.Errorf("expected %d introspection requests, got: %d", tt.wantRequests, n)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+			if data, _ := acMap["opaque"].(map[string]interface{}); data["sub"] != "john" {
+				subT.Errorf("expected the introspection response in the request context, got: %v", acMap)
+			}
+
+			if scopes, _ := req.Context().Value(request.Scopes).([]string); len(scopes) != 2 {
+				subT.Errorf("expected the granted scopes, got: %v", scopes)
+			}
+		})
+	}
+}
+
+func TestIntrospection_ConcurrentLookups(t *testing.T) {
+	var requests int32
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(time.Millisecond * 100)
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`{"active": true, "sub": "john"}`))
+	}))
+	defer origin.Close()
+
+	introspection, err := ac.NewIntrospection(&ac.IntrospectionOptions{
+		Backend:  http.DefaultTransport,
+		Endpoint: origin.URL + "/introspect",
+		Name:     "opaque",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 5; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer concurrent")
+			if verr := introspection.Validate(req); verr != nil {
+				t.Error(verr)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected 1 introspection request, got: %d", n)
+	}
+}
+
+func TestIntrospection_CanceledLookup(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(time.Millisecond * 200)
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`{"active": true, "sub": "john"}`))
+	}))
+	defer origin.Close()
+
+	introspection, err := ac.NewIntrospection(&ac.IntrospectionOptions{
+		Backend:  http.DefaultTransport,
+		Endpoint: origin.URL + "/introspect",
+		Name:     "opaque",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRequest := func(ctx context.Context) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		req.Header.Set("Authorization", "Bearer canceled")
+		return req
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		first <- introspection.Validate(newRequest(ctx))
+	}()
+
+	time.Sleep(time.Millisecond * 50)
+	second := make(chan error)
+	go func() {
+		second <- introspection.Validate(newRequest(context.Background()))
+	}()
+
+	// the first client goes away while the second one waits for the shared lookup
+	time.Sleep(time.Millisecond * 50)
+	cancel()
+
+	if err = <-first; err == nil {
+		t.Error("expected an error for the canceled request")
+	}
+
+	if err = <-second; err != nil {
+		t.Errorf("expected the waiting request to pass, got: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package accesscontrol
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/utils"
+)
+
+var (
+	ErrorIPDenied = errors.New("ip_filter: client address denied")
+
+	_ AccessControl = &IPFilter{}
+)
+
+// IPFilterOptions represents the configuration of an <IPFilter> access control. The
+// optional files contain one address or CIDR range per line and are reloaded on changes.
+type IPFilterOptions struct {
+	Allow     []string
+	AllowFile string
+	Deny      []string
+	DenyFile  string
+	Log       *logrus.Entry
+}
+
+// IPFilter matches the client address against allow and deny lists. Denied ranges take
+// precedence, configured allow lists require a matching range.
+type IPFilter struct {
+	allow     *ipList
+	allowFile *reloadableFile
+	deny      *ipList
+	denyFile  *reloadableFile
+}
+
+type ipList []*net.IPNet
+
+// NewIPFilter creates a new <*IPFilter> object.
+func NewIPFilter(opts *IPFilterOptions) (*IPFilter, error) {
+	if len(opts.Allow) == 0 && opts.AllowFile == "" && len(opts.Deny) == 0 && opts.DenyFile == "" {
+		return nil, fmt.Errorf("ip_filter: at least one allow or deny list is required")
+	}
+
+	f := &IPFilter{}
+
+	var err error
+	if f.allow, err = parseIPList(opts.Allow); err != nil {
+		return nil, err
+	}
+
+	if f.deny, err = parseIPList(opts.Deny); err != nil {
+		return nil, err
+	}
+
+	if opts.AllowFile != "" {
+		if f.allowFile, err = newReloadableFile(opts.AllowFile, parseIPListFile, opts.Log); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.DenyFile != "" {
+		if f.denyFile, err = newReloadableFile(opts.DenyFile, parseIPListFile, opts.Log); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// Validate implements the <AccessControl> interface.
+func (f *IPFilter) Validate(req *http.Request) error {
+	ip := net.ParseIP(utils.ClientIP(req))
+	if ip == nil {
+		return ErrorIPDenied
+	}
+
+	deny := f.deny
+	if f.denyFile != nil {
+		deny = deny.merge(f.denyFile.get().(*ipList))
+	}
+
+	if deny.contains(ip) {
+		return ErrorIPDenied
+	}
+
+	allow := f.allow
+	if f.allowFile != nil {
+		allow = allow.merge(f.allowFile.get().(*ipList))
+	}
+
+	if (len(*allow) > 0 || f.allowFile != nil) && !allow.contains(ip) {
+		return ErrorIPDenied
+	}
+
+	return nil
+}
+
+func (l *ipList) contains(ip net.IP) bool {
+	for _, n := range *l {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *ipList) merge(other *ipList) *ipList {
+	result := make(ipList, 0, len(*l)+len(*other))
+	result = append(result, *l...)
+	result = append(result, *other...)
+	return &result
+}
+
+// parseIPList parses the given addresses and CIDR ranges, addresses match exactly.
+func parseIPList(entries []string) (*ipList, error) {
+	list := make(ipList, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("ip_filter: invalid address: %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			list = append(list, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("ip_filter: invalid range: %q", entry)
+		}
+		list = append(list, ipNet)
+	}
+	return &list, nil
+}
+
+// parseIPListFile parses a file with one address or CIDR range per line, '#' starts a comment.
+func parseIPListFile(content []byte) (interface{}, error) {
+	var entries []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			entries = append(entries, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return parseIPList(entries)
+}
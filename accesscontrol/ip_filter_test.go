@@ -0,0 +1,74 @@
+package accesscontrol_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ac "github.com/avenga/couper/accesscontrol"
+)
+
+func TestIPFilter_Validate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ip_filter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	allowFile := filepath.Join(dir, "allow.txt")
+	if err = ioutil.WriteFile(allowFile, []byte("# partners\n203.0.113.0/24\n\n2001:db8::/32 # v6\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     *ac.IPFilterOptions
+		addr     string
+		wantDeny bool
+	}{
+		{"allow range", &ac.IPFilterOptions{Allow: []string{"10.0.0.0/8"}}, "10.1.2.3:1234", false},
+		{"allow range mismatch", &ac.IPFilterOptions{Allow: []string{"10.0.0.0/8"}}, "192.168.1.1:1234", true},
+		{"allow address", &ac.IPFilterOptions{Allow: []string{"192.168.1.1"}}, "192.168.1.1:1234", false},
+		{"allow address mismatch", &ac.IPFilterOptions{Allow: []string{"192.168.1.1"}}, "192.168.1.2:1234", true},
+		{"deny only", &ac.IPFilterOptions{Deny: []string{"192.168.0.0/16"}}, "10.1.2.3:1234", false},
+		{"deny precedence", &ac.IPFilterOptions{Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.1"}}, "10.0.0.1:1234", true},
+		{"ipv6", &ac.IPFilterOptions{Allow: []string{"::1"}}, "[::1]:1234", false},
+		{"allow file", &ac.IPFilterOptions{AllowFile: allowFile}, "203.0.113.7:1234", false},
+		{"allow file v6", &ac.IPFilterOptions{AllowFile: allowFile}, "[2001:db8::1]:1234", false},
+		{"allow file mismatch", &ac.IPFilterOptions{AllowFile: allowFile}, "10.0.0.1:1234", true},
+		{"deny file", &ac.IPFilterOptions{DenyFile: allowFile}, "203.0.113.7:1234", true},
+		{"invalid client address", &ac.IPFilterOptions{Deny: []string{"10.0.0.1"}}, "unknown", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			f, err := ac.NewIPFilter(tt.opts)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.addr
+
+			err = f.Validate(req)
+			if tt.wantDeny && err != ac.ErrorIPDenied {
+				subT.Errorf("expected a denied address, got: %v", err)
+			} else if !tt.wantDeny && err != nil {
+				subT.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	for _, invalid := range []*ac.IPFilterOptions{
+		{},
+		{Allow: []string{"10.0.0.0/33"}},
+		{Deny: []string{"localhost"}},
+	} {
+		if _, err = ac.NewIPFilter(invalid); err == nil {
+			t.Errorf("expected a configuration error for: %v", invalid)
+		}
+	}
+}
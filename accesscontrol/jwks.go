@@ -0,0 +1,370 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/avenga/couper/config/request"
+)
+
+const (
+	// DefaultJWKSTTL is the lifetime of a fetched key set before it gets refreshed in the background.
+	DefaultJWKSTTL = time.Hour
+	// DefaultJWKSRefetchInterval limits the key set requests caused by unknown key ids or fetch errors.
+	DefaultJWKSRefetchInterval = time.Second * 10
+)
+
+var ErrorJWKSUnavailable = errors.New("jwks: key set unavailable")
+
+// JWKSOptions represents the key set sources and the refresh behaviour of a <JWKS>.
+// The optional Context is the parent of the key set requests which are not bound to a client request.
+type JWKSOptions struct {
+	Backend         http.RoundTripper
+	Context         context.Context
+	File            []byte
+	RefetchInterval time.Duration
+	TTL             time.Duration
+	URL             string
+}
+
+// JWK represents a JSON Web Key (RFC 7517) which is used to verify token signatures.
+type JWK struct {
+	Algorithm string
+	Key       interface{}
+	KeyID     string
+}
+
+// JWKS provides the verification keys of a JSON Web Key Set. Keys from an URL are
+// cached for the configured TTL and refreshed in the background afterwards.
+// Unknown key ids trigger a refetch which is limited by the refetch interval.
+type JWKS struct {
+	options *JWKSOptions
+	static  []*JWK
+
+	fetchMu    sync.Mutex
+	mu         sync.RWMutex
+	fetchedAt  time.Time
+	keys       []*JWK
+	lastFetch  time.Time
+	refreshing bool
+}
+
+// NewJWKS creates a new <*JWKS> object. The keys of the given file content are
+// always available, keys from the URL are fetched via the given backend on demand.
+func NewJWKS(opts *JWKSOptions) (*JWKS, error) {
+	if opts.URL == "" && len(opts.File) == 0 {
+		return nil, fmt.Errorf("jwks: either jwks_url or jwks_file must be specified")
+	}
+
+	if opts.URL != "" && opts.Backend == nil {
+		return nil, fmt.Errorf("jwks: missing backend for jwks_url")
+	}
+
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultJWKSTTL
+	}
+
+	if opts.RefetchInterval <= 0 {
+		opts.RefetchInterval = DefaultJWKSRefetchInterval
+	}
+
+	jwks := &JWKS{options: opts}
+
+	if len(opts.File) > 0 {
+		keys, err := ParseJWKS(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		jwks.static = keys
+	}
+
+	return jwks, nil
+}
+
+// GetKey returns the verification key for the given key id and signature algorithm.
+// An unknown key id results in a refetch of the key set if the refetch interval has been passed.
+func (j *JWKS) GetKey(kid, algorithm string) (interface{}, error) {
+	algo := NewAlgorithm(algorithm)
+	if algo == AlgorithmUnknown || algo.IsHMAC() {
+		return nil, fmt.Errorf("jwks: unsupported signature algorithm: %q", algorithm)
+	}
+
+	keys, err := j.keySet()
+	if key := findKey(keys, kid, algo); key != nil {
+		return key, nil
+	}
+
+	if j.options.URL != "" && err == nil {
+		if keys, err = j.refetch(); err == nil {
+			if key := findKey(keys, kid, algo); key != nil {
+				return key, nil
+			}
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("jwks: no matching key found for kid %q and algorithm %s", kid, algo)
+}
+
+// keySet returns the current keys and triggers the initial fetch or a background refresh.
+func (j *JWKS) keySet() ([]*JWK, error) {
+	if j.options.URL == "" {
+		return j.static, nil
+	}
+
+	j.mu.RLock()
+	keys, fetchedAt := j.keys, j.fetchedAt
+	j.mu.RUnlock()
+
+	if fetchedAt.IsZero() {
+		return j.refetch()
+	}
+
+	if time.Since(fetchedAt) > j.options.TTL {
+		j.mu.Lock()
+		if !j.refreshing {
+			j.refreshing = true
+			go j.refresh()
+		}
+		j.mu.Unlock()
+	}
+
+	return j.withStatic(keys), nil
+}
+
+func (j *JWKS) refresh() {
+	defer func() {
+		j.mu.Lock()
+		j.refreshing = false
+		j.mu.Unlock()
+	}()
+	// errors are logged by the backend, the stale keys stay in use
+	_, _ = j.refetch()
+}
+
+// refetch loads the key set unless the last attempt is within the refetch interval.
+func (j *JWKS) refetch() ([]*JWK, error) {
+	j.fetchMu.Lock()
+	defer j.fetchMu.Unlock()
+
+	j.mu.RLock()
+	keys, fetchedAt, lastFetch := j.keys, j.fetchedAt, j.lastFetch
+	j.mu.RUnlock()
+
+	if !lastFetch.IsZero() && time.Since(lastFetch) < j.options.RefetchInterval {
+		if fetchedAt.IsZero() {
+			return j.static, ErrorJWKSUnavailable
+		}
+		return j.withStatic(keys), nil
+	}
+
+	fetched, err := j.fetch()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.lastFetch = time.Now()
+	if err != nil {
+		if j.fetchedAt.IsZero() {
+			return j.static, fmt.Errorf("%w: %v", ErrorJWKSUnavailable, err)
+		}
+		return j.withStatic(j.keys), nil
+	}
+
+	j.keys = fetched
+	j.fetchedAt = j.lastFetch
+	return j.withStatic(j.keys), nil
+}
+
+// withStatic returns a new slice of the given keys and the keys of the file.
+func (j *JWKS) withStatic(keys []*JWK) []*JWK {
+	result := make([]*JWK, 0, len(keys)+len(j.static))
+	result = append(result, keys...)
+	return append(result, j.static...)
+}
+
+func (j *JWKS) fetch() ([]*JWK, error) {
+	ctx := context.WithValue(j.options.Context, request.RoundTripName, "jwks")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.options.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := j.options.Backend.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", res.StatusCode)
+	}
+
+	return ParseJWKS(body)
+}
+
+func findKey(keys []*JWK, kid string, algo Algorithm) interface{} {
+	for _, k := range keys {
+		if kid != "" && k.KeyID != kid {
+			continue
+		}
+		if k.Algorithm != "" && k.Algorithm != algo.String() {
+			continue
+		}
+		if algo.checkKeyType(k.Key) != nil {
+			continue
+		}
+		return k.Key
+	}
+	return nil
+}
+
+type jsonWebKey struct {
+	Alg string   `json:"alg"`
+	Crv string   `json:"crv"`
+	E   string   `json:"e"`
+	Kid string   `json:"kid"`
+	Kty string   `json:"kty"`
+	N   string   `json:"n"`
+	Use string   `json:"use"`
+	X   string   `json:"x"`
+	X5c []string `json:"x5c"`
+	Y   string   `json:"y"`
+}
+
+// ParseJWKS parses the signature verification keys of the given JSON Web Key Set.
+// Encryption keys and unsupported key types are skipped.
+func ParseJWKS(data []byte) ([]*JWK, error) {
+	set := &struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := json.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("jwks: invalid key set: %v", err)
+	}
+
+	var keys []*JWK
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwks: key %q: %v", k.Kid, err)
+		}
+		if key == nil {
+			continue
+		}
+
+		keys = append(keys, &JWK{Algorithm: k.Alg, Key: key, KeyID: k.Kid})
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		if k.N == "" && len(k.X5c) > 0 {
+			return k.certificateKey()
+		}
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.X == "" && len(k.X5c) > 0 {
+			return k.certificateKey()
+		}
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("invalid point for curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve: %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 public key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default: // e.g. symmetric keys
+		return nil, nil
+	}
+}
+
+func (k jsonWebKey) certificateKey() (interface{}, error) {
+	der, err := base64.StdEncoding.DecodeString(k.X5c[0])
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return cert.PublicKey, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing key parameter")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,280 @@
+package accesscontrol_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	ac "github.com/avenga/couper/accesscontrol"
+)
+
+type testJWKSServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	keys     []map[string]interface{}
+	requests int32
+}
+
+func newTestJWKSServer(keys ...map[string]interface{}) *testJWKSServer {
+	s := &testJWKSServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&s.requests, 1)
+		s.mu.Lock()
+		b, _ := json.Marshal(map[string]interface{}{"keys": s.keys})
+		s.mu.Unlock()
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write(b)
+	}))
+	return s
+}
+
+func (s *testJWKSServer) setKeys(keys ...map[string]interface{}) {
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+func (s *testJWKSServer) count() int {
+	return int(atomic.LoadInt32(&s.requests))
+}
+
+func newRSAJWK(kid string, key *rsa.PrivateKey) map[string]interface{} {
+	return map[string]interface{}{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func newToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+	tok := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "me"})
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+	token, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func validate(j *ac.JWT, token string) error {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return j.Validate(req)
+}
+
+func TestJWKS_KeySelection(t *testing.T) {
+	_, rsaKey := newRSAKeyPair()
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPubKey, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwksFile, err := json.Marshal(map[string]interface{}{"keys": []map[string]interface{}{
+		newRSAJWK("rsa", rsaKey),
+		{
+			"kty": "EC", "kid": "ec", "crv": "P-256", "alg": "ES256",
+			"x": base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+			"y": base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+		},
+		{
+			"kty": "OKP", "kid": "ed", "crv": "Ed25519",
+			"x": base64.RawURLEncoding.EncodeToString(edPubKey),
+		},
+		{"kty": "oct", "kid": "secret", "k": "c2VjcmV0"},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, err := ac.NewJWKS(&ac.JWKSOptions{File: jwksFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := ac.NewJWTFromJWKS("", "test_ac", nil, nil, ac.Header, "Authorization", jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"RS256 kid", newToken(t, jwt.SigningMethodRS256, "rsa", rsaKey), false},
+		{"PS384 kid", newToken(t, jwt.SigningMethodPS384, "rsa", rsaKey), false},
+		{"ES256 kid", newToken(t, jwt.SigningMethodES256, "ec", ecKey), false},
+		{"EdDSA kid", newToken(t, jwt.GetSigningMethod("EdDSA"), "ed", edKey), false},
+		{"RS256 without kid", newToken(t, jwt.SigningMethodRS256, "", rsaKey), false},
+		{"unknown kid", newToken(t, jwt.SigningMethodRS256, "unknown", rsaKey), true},
+		{"key type mismatch", newToken(t, jwt.SigningMethodRS256, "ec", rsaKey), true},
+		{"HMAC", newToken(t, jwt.SigningMethodHS256, "secret", []byte("secret")), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			if err = validate(j, tt.token); (err != nil) != tt.wantErr {
+				subT.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	j, err = ac.NewJWTFromJWKS("ES256", "test_ac", nil, nil, ac.Header, "Authorization", jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = validate(j, newToken(t, jwt.SigningMethodRS256, "rsa", rsaKey)); err == nil {
+		t.Error("expected an error for an algorithm other than the configured signature_algorithm")
+	}
+}
+
+func TestJWKS_Rotation(t *testing.T) {
+	_, key1 := newRSAKeyPair()
+	_, key2 := newRSAKeyPair()
+
+	srv := newTestJWKSServer(newRSAJWK("key1", key1))
+	defer srv.Close()
+
+	jwks, err := ac.NewJWKS(&ac.JWKSOptions{
+		Backend:         http.DefaultTransport,
+		RefetchInterval: time.Millisecond * 200,
+		URL:             srv.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := ac.NewJWTFromJWKS("RS256", "test_ac", nil, nil, ac.Header, "Authorization", jwks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if srv.count() != 0 {
+		t.Error("expected the key set to be fetched on demand")
+	}
+
+	if err = validate(j, newToken(t, jwt.SigningMethodRS256, "key1", key1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = validate(j, newToken(t, jwt.SigningMethodRS256, "key1", key1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if srv.count() != 1 {
+		t.Errorf("expected a cached key set, got %d requests", srv.count())
+	}
+
+	srv.setKeys(newRSAJWK("key2", key2))
+	token2 := newToken(t, jwt.SigningMethodRS256, "key2", key2)
+
+	// unknown kid within the refetch interval
+	if err = validate(j, token2); err == nil {
+		t.Error("expected an error for the unknown kid")
+	}
+
+	if srv.count() != 1 {
+		t.Errorf("expected a rate-limited refetch, got %d requests", srv.count())
+	}
+
+	time.Sleep(time.Millisecond * 250)
+
+	if err = validate(j, token2); err != nil {
+		t.Errorf("expected the rotated key to be fetched: %v", err)
+	}
+
+	if srv.count() != 2 {
+		t.Errorf("expected a refetch for the unknown kid, got %d requests", srv.count())
+	}
+
+	if err = validate(j, newToken(t, jwt.SigningMethodRS256, "key1", key1)); err == nil {
+		t.Error("expected an error for the removed key")
+	}
+}
+
+func TestJWKS_BackgroundRefresh(t *testing.T) {
+	_, key1 := newRSAKeyPair()
+	_, key2 := newRSAKeyPair()
+
+	srv := newTestJWKSServer(newRSAJWK("key1", key1))
+	defer srv.Close()
+
+	jwks, err := ac.NewJWKS(&ac.JWKSOptions{
+		Backend:         http.DefaultTransport,
+		RefetchInterval: time.Millisecond * 10,
+		TTL:             time.Millisecond * 100,
+		URL:             srv.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = jwks.GetKey("key1", "RS256"); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.setKeys(newRSAJWK("key1", key2))
+	time.Sleep(time.Millisecond * 150)
+
+	// the stale key is returned while the key set gets refreshed
+	key, err := jwks.GetKey("key1", "RS256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.(*rsa.PublicKey).N.Cmp(key1.N) != 0 {
+		t.Error("expected the stale key")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		key, err = jwks.GetKey("key1", "RS256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.(*rsa.PublicKey).N.Cmp(key2.N) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the refreshed key")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestJWKS_Unavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	jwks, err := ac.NewJWKS(&ac.JWKSOptions{
+		Backend: http.DefaultTransport,
+		URL:     srv.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = jwks.GetKey("key1", "RS256"); err == nil {
+		t.Error("expected an unavailable key set error")
+	}
+}
@@ -1,26 +1,33 @@
 package accesscontrol
 
 import (
-	"context"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/base64"
-	"encoding/pem"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/hashicorp/hcl/v2"
 
-	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/eval"
 )
 
+// DefaultScopeClaim is the token claim with the space separated list of granted scopes.
+const DefaultScopeClaim = "scope"
+
 const (
 	Unknown Source = iota - 1
 	Cookie
 	Header
+	QueryParam
+	PostParam
 )
 
 var (
@@ -28,10 +35,18 @@ var (
 	ErrorEmptyToken     = errors.New("empty token")
 	ErrorMissingKey     = errors.New("either key_file or key must be specified")
 	ErrorNotConfigured  = errors.New("jwt handler not configured")
-	ErrorNotSupported   = errors.New("only RSA and HMAC key encodings are supported")
+	ErrorNotSupported   = errors.New("only RSA, ECDSA, Ed25519 and HMAC key encodings are supported")
+	ErrorUnbufferedBody = errors.New("post_param requires a buffered request body")
 	ErrorUnknownSource  = errors.New("unknown source definition")
 
 	_ AccessControl = &JWT{}
+
+	asymmetricAlgorithms = []string{
+		AlgorithmRSA256.String(), AlgorithmRSA384.String(), AlgorithmRSA512.String(),
+		AlgorithmRSAPSS256.String(), AlgorithmRSAPSS384.String(), AlgorithmRSAPSS512.String(),
+		AlgorithmECDSA256.String(), AlgorithmECDSA384.String(), AlgorithmECDSA512.String(),
+		AlgorithmEdDSA.String(),
+	}
 )
 
 type (
@@ -42,14 +57,20 @@ type (
 type JWT struct {
 	algorithm      Algorithm
 	claims         map[string]interface{}
+	claimsExpr     hcl.Expression
 	claimsRequired []string
 	ignoreExp      bool
 	source         Source
 	sourceKey      string
+	stripToken     bool
 	hmacSecret     []byte
+	jwks           *JWKS
 	name           string
 	parser         *jwt.Parser
-	pubKey         *rsa.PublicKey
+	pubKey         interface{}
+	realm          string
+	rolesClaim     string
+	scopeClaim     string
 }
 
 // NewJWT parses the key and creates Validation obj which can be referenced in related handlers.
@@ -67,18 +88,14 @@ func NewJWT(algorithm, name string, claims map[string]interface{}, reqClaims []s
 		return nil, ErrorNotSupported
 	}
 
-	parser, err := newParser(algo, claims)
-	if err != nil {
-		return nil, err
-	}
-
 	jwtObj := &JWT{
 		algorithm:      algo,
 		claims:         claims,
 		claimsRequired: reqClaims,
 		hmacSecret:     key,
 		name:           name,
-		parser:         parser,
+		parser:         newParser([]string{algo.String()}),
+		scopeClaim:     DefaultScopeClaim,
 		source:         src,
 		sourceKey:      srcKey,
 	}
@@ -87,35 +104,70 @@ func NewJWT(algorithm, name string, claims map[string]interface{}, reqClaims []s
 		return jwtObj, nil
 	}
 
-	pubKey, err := parsePublicPEMKey(key)
-	if err != nil && (err != jwt.ErrKeyMustBePEMEncoded || err != jwt.ErrNotRSAPublicKey) {
-		cert, err := x509.ParseCertificate(key)
-		if err != nil {
-			decKey, err := base64.StdEncoding.DecodeString(string(key))
-			if err != nil {
-				return nil, ErrorNotSupported
-			}
-			cert, err = x509.ParseCertificate(decKey)
-			if err != nil {
-				return nil, err
-			}
-		}
-		rsaPubKey, _ := cert.PublicKey.(*rsa.PublicKey)
-		pubKey = &rsa.PublicKey{N: rsaPubKey.N, E: rsaPubKey.E}
+	pubKey, err := parsePublicKey(key)
+	if err != nil {
+		return nil, err
 	}
+
+	if err = algo.checkKeyType(pubKey); err != nil {
+		return nil, err
+	}
+
 	jwtObj.pubKey = pubKey
-	return jwtObj, err
+	return jwtObj, nil
+}
+
+// NewJWTFromJWKS creates a Validation obj which selects the verification key of the given key set
+// by the "kid" token header. An empty algorithm allows all asymmetric algorithms.
+func NewJWTFromJWKS(algorithm, name string, claims map[string]interface{}, reqClaims []string, src Source, srcKey string, jwks *JWKS) (*JWT, error) {
+	if jwks == nil {
+		return nil, ErrorMissingKey
+	}
+
+	if src == Unknown {
+		return nil, ErrorUnknownSource
+	}
+
+	algo := AlgorithmUnknown
+	validMethods := asymmetricAlgorithms
+	if algorithm != "" {
+		if algo = NewAlgorithm(algorithm); algo == AlgorithmUnknown || algo.IsHMAC() {
+			return nil, fmt.Errorf("jwks: unsupported signature algorithm: %q", algorithm)
+		}
+		validMethods = []string{algo.String()}
+	}
+
+	return &JWT{
+		algorithm:      algo,
+		claims:         claims,
+		claimsRequired: reqClaims,
+		jwks:           jwks,
+		name:           name,
+		parser:         newParser(validMethods),
+		scopeClaim:     DefaultScopeClaim,
+		source:         src,
+		sourceKey:      srcKey,
+	}, nil
 }
 
 // Validate reading the token from configured source and validates against the key.
+// Failures of bearer tokens, all sources except cookies, are returned as <*BearerError>.
 func (j *JWT) Validate(req *http.Request) error {
-	var tokenValue string
-	var err error
-
 	if j == nil {
 		return ErrorNotConfigured
 	}
 
+	err := j.validate(req)
+	if err != nil && j.source != Cookie {
+		return &BearerError{error: err, Realm: j.realm}
+	}
+	return err
+}
+
+func (j *JWT) validate(req *http.Request) error {
+	var tokenValue string
+	var err error
+
 	switch j.source {
 	case Cookie:
 		if cookie, err := req.Cookie(j.sourceKey); err != nil && err != http.ErrNoCookie {
@@ -135,48 +187,163 @@ func (j *JWT) Validate(req *http.Request) error {
 		} else {
 			tokenValue = req.Header.Get(j.sourceKey)
 		}
+	case QueryParam:
+		tokenValue = req.URL.Query().Get(j.sourceKey)
+	case PostParam:
+		var form url.Values
+		if form, err = readForm(req); err != nil {
+			return err
+		}
+		tokenValue = form.Get(j.sourceKey)
 	}
 
-	// TODO j.PostParam, j.QueryParam
 	if tokenValue == "" {
 		return ErrorEmptyToken
 	}
 
-	token, err := j.parser.ParseWithClaims(tokenValue, jwt.MapClaims{}, j.getValidationKey)
+	tokenClaims, err := j.validateToken(req, tokenValue)
 	if err != nil {
 		return err
 	}
 
-	tokenClaims, err := j.validateClaims(token)
-	if err != nil {
-		return err
+	if j.stripToken {
+		if err = j.removeToken(req); err != nil {
+			return err
+		}
 	}
 
-	ctx := req.Context()
-	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
-	if !ok {
-		acMap = make(map[string]interface{})
+	setAccessControlContext(req, j.name, tokenClaims)
+
+	for _, claim := range []string{j.scopeClaim, j.rolesClaim} {
+		if claim == "" {
+			continue
+		}
+		if val, exist := lookupClaim(tokenClaims, claim); exist {
+			grantScopes(req, val)
+		}
 	}
-	acMap[j.name] = tokenClaims
 
-	ctx = context.WithValue(ctx, request.AccessControls, acMap)
-	*req = *req.WithContext(ctx)
+	return nil
+}
+
+// SetScopeClaims sets the claims which grant scopes for the <RequiredScope> access control.
+// An empty scopeClaim keeps the <DefaultScopeClaim>, roles are granted as scopes too.
+func (j *JWT) SetScopeClaims(scopeClaim, rolesClaim string) {
+	if scopeClaim != "" {
+		j.scopeClaim = scopeClaim
+	}
+	j.rolesClaim = rolesClaim
+}
 
+// SetRealm sets the realm of the "WWW-Authenticate: Bearer" challenge.
+func (j *JWT) SetRealm(realm string) {
+	j.realm = realm
+}
+
+// SetClaimsExpression sets the claims which are evaluated per request, e.g. to compare
+// claims with request variables. The expression takes precedence over the static claims.
+func (j *JWT) SetClaimsExpression(expr hcl.Expression) {
+	j.claimsExpr = expr
+}
+
+// StripToken enables the removal of the token query or post parameter after a
+// successful validation, so the token does not get passed to the origin.
+func (j *JWT) StripToken() {
+	j.stripToken = true
+}
+
+func (j *JWT) removeToken(req *http.Request) error {
+	switch j.source {
+	case QueryParam:
+		query := req.URL.Query()
+		query.Del(j.sourceKey)
+		req.URL.RawQuery = query.Encode()
+	case PostParam:
+		form, err := readForm(req)
+		if err != nil {
+			return err
+		}
+		form.Del(j.sourceKey)
+
+		body := []byte(form.Encode())
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.Body, _ = req.GetBody()
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	default:
+		return nil
+	}
+
+	// parsed values are cached and would still contain the token
+	req.Form, req.PostForm = nil, nil
+
+	// the req.query and req.post variables must not contain the token either
+	if evalCtx, ok := req.Context().Value(eval.ContextType).(*eval.Context); ok {
+		*req = *req.WithContext(evalCtx.WithClientRequest(req))
+	}
 	return nil
 }
 
-func (j *JWT) getValidationKey(_ *jwt.Token) (interface{}, error) {
-	switch j.algorithm {
-	case AlgorithmRSA256, AlgorithmRSA384, AlgorithmRSA512:
-		return j.pubKey, nil
-	case AlgorithmHMAC256, AlgorithmHMAC384, AlgorithmHMAC512:
+// readForm parses the url-encoded form body of the given request without consuming it.
+func readForm(req *http.Request) (url.Values, error) {
+	switch req.Method {
+	case http.MethodPatch, http.MethodPost, http.MethodPut:
+	default:
+		return url.Values{}, nil
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != "application/x-www-form-urlencoded" {
+		return url.Values{}, nil
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return url.Values{}, nil
+	}
+
+	if req.GetBody == nil {
+		return nil, ErrorUnbufferedBody
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return url.ParseQuery(string(b))
+}
+
+func (j *JWT) getValidationKey(token *jwt.Token) (interface{}, error) {
+	switch {
+	case j.jwks != nil:
+		kid, _ := token.Header["kid"].(string)
+		return j.jwks.GetKey(kid, token.Method.Alg())
+	case j.algorithm.IsHMAC():
 		return j.hmacSecret, nil
+	case j.pubKey != nil:
+		return j.pubKey, nil
 	default:
 		return nil, ErrorNotSupported
 	}
 }
 
-func (j *JWT) validateClaims(token *jwt.Token) (map[string]interface{}, error) {
+// validateToken parses the given token, verifies its signature and validates the claims.
+func (j *JWT) validateToken(req *http.Request, tokenValue string) (map[string]interface{}, error) {
+	token, err := j.parser.ParseWithClaims(tokenValue, jwt.MapClaims{}, j.getValidationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return j.validateClaims(req, token)
+}
+
+func (j *JWT) validateClaims(req *http.Request, token *jwt.Token) (map[string]interface{}, error) {
 	var tokenClaims jwt.MapClaims
 	if tc, ok := token.Claims.(jwt.MapClaims); ok {
 		tokenClaims = tc
@@ -188,23 +355,26 @@ func (j *JWT) validateClaims(token *jwt.Token) (map[string]interface{}, error) {
 
 	for _, key := range j.claimsRequired {
 		if _, ok := tokenClaims[key]; !ok {
-			return nil, &jwt.InvalidClaimsError{Message: "required claim is missing: " + key}
+			return nil, &ClaimError{Claim: key, Message: "required claim is missing", Missing: true}
 		}
 	}
 
-	for k, v := range j.claims {
-
-		if k == "iss" || k == "aud" { // gets validated during parsing
-			continue
+	claims := j.claims
+	if j.claimsExpr != nil {
+		var err error
+		if claims, err = evalClaims(req, j.claimsExpr); err != nil {
+			return nil, err
 		}
+	}
 
-		val, exist := tokenClaims[k]
+	for k, v := range claims {
+		val, exist := lookupClaim(tokenClaims, k)
 		if !exist {
-			return nil, errors.New("expected claim not found: '" + k + "'")
+			return nil, &ClaimError{Claim: k, Message: "expected claim not found", Missing: true}
 		}
 
-		if val != v {
-			return nil, errors.New("unexpected value for claim '" + k + "'")
+		if !matchClaim(v, val) {
+			return nil, &ClaimError{Claim: k, Message: "unexpected value"}
 		}
 	}
 	return tokenClaims, nil
@@ -218,76 +388,11 @@ func getBearer(val string) (string, error) {
 	return "", ErrorBearerRequired
 }
 
-func newParser(algo Algorithm, claims map[string]interface{}) (*jwt.Parser, error) {
-	options := []jwt.ParserOption{
-		jwt.WithValidMethods([]string{algo.String()}),
+func newParser(validMethods []string) *jwt.Parser {
+	// the audience is validated with the other claims
+	return jwt.NewParser(
+		jwt.WithValidMethods(validMethods),
 		jwt.WithLeeway(time.Second),
-	}
-
-	if claims == nil {
-		options = append(options, jwt.WithoutAudienceValidation())
-		return jwt.NewParser(options...), nil
-	}
-
-	if iss, ok := claims["iss"]; ok {
-		if err := isStringType(iss); err != nil {
-			return nil, fmt.Errorf("iss: %w", err)
-		}
-		options = append(options, jwt.WithIssuer(iss.(string)))
-	}
-	if aud, ok := claims["aud"]; ok {
-		if err := isStringType(aud); err != nil {
-			return nil, fmt.Errorf("aud: %w", err)
-		}
-		options = append(options, jwt.WithAudience(aud.(string)))
-	} else {
-		options = append(options, jwt.WithoutAudienceValidation())
-	}
-
-	return jwt.NewParser(options...), nil
-}
-
-// parsePublicPEMKey tries to parse all supported publicKey variations which
-// must be given in PEM encoded format.
-func parsePublicPEMKey(key []byte) (pub *rsa.PublicKey, err error) {
-	pemBlock, _ := pem.Decode(key)
-	if pemBlock == nil {
-		decKey, err := base64.StdEncoding.DecodeString(string(key))
-		if err != nil {
-			return nil, ErrorNotSupported
-		}
-		pemBlock, _ = pem.Decode(decKey)
-		if pemBlock == nil {
-			return nil, jwt.ErrKeyMustBePEMEncoded
-		}
-	}
-	pubKey, pubErr := x509.ParsePKCS1PublicKey(pemBlock.Bytes)
-	if pubErr != nil {
-		pkixKey, err := x509.ParsePKIXPublicKey(pemBlock.Bytes)
-		if err != nil {
-			cert, cerr := x509.ParseCertificate(pemBlock.Bytes)
-			if cerr != nil {
-				return nil, jwt.ErrNotRSAPublicKey
-			}
-			if k, ok := cert.PublicKey.(*rsa.PublicKey); ok {
-				return k, nil
-			}
-			return nil, jwt.ErrNotRSAPublicKey
-		}
-		if k, ok := pkixKey.(*rsa.PublicKey); !ok {
-			return nil, jwt.ErrNotRSAPublicKey
-		} else {
-			pubKey = k
-		}
-	}
-	return pubKey, nil
-}
-
-func isStringType(val interface{}) error {
-	switch val.(type) {
-	case string:
-		return nil
-	default:
-		return errors.New("expected a string value type")
-	}
+		jwt.WithoutAudienceValidation(),
+	)
 }
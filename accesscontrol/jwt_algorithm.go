@@ -1,5 +1,13 @@
 package accesscontrol
 
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+)
+
 const (
 	AlgorithmUnknown Algorithm = iota - 1
 	_
@@ -9,6 +17,13 @@ const (
 	AlgorithmHMAC256
 	AlgorithmHMAC384
 	AlgorithmHMAC512
+	AlgorithmECDSA256
+	AlgorithmECDSA384
+	AlgorithmECDSA512
+	AlgorithmRSAPSS256
+	AlgorithmRSAPSS384
+	AlgorithmRSAPSS512
+	AlgorithmEdDSA
 )
 
 func NewAlgorithm(a string) Algorithm {
@@ -25,6 +40,20 @@ func NewAlgorithm(a string) Algorithm {
 		return AlgorithmHMAC384
 	case "HS512":
 		return AlgorithmHMAC512
+	case "ES256":
+		return AlgorithmECDSA256
+	case "ES384":
+		return AlgorithmECDSA384
+	case "ES512":
+		return AlgorithmECDSA512
+	case "PS256":
+		return AlgorithmRSAPSS256
+	case "PS384":
+		return AlgorithmRSAPSS384
+	case "PS512":
+		return AlgorithmRSAPSS512
+	case "EdDSA":
+		return AlgorithmEdDSA
 	default:
 		return AlgorithmUnknown
 	}
@@ -39,6 +68,26 @@ func (a Algorithm) IsHMAC() bool {
 	}
 }
 
+func (a Algorithm) IsECDSA() bool {
+	switch a {
+	case AlgorithmECDSA256, AlgorithmECDSA384, AlgorithmECDSA512:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRSA returns true for the PKCS1v15 and the PSS variants.
+func (a Algorithm) IsRSA() bool {
+	switch a {
+	case AlgorithmRSA256, AlgorithmRSA384, AlgorithmRSA512,
+		AlgorithmRSAPSS256, AlgorithmRSAPSS384, AlgorithmRSAPSS512:
+		return true
+	default:
+		return false
+	}
+}
+
 func (a Algorithm) String() string {
 	switch a {
 	case AlgorithmRSA256:
@@ -53,7 +102,78 @@ func (a Algorithm) String() string {
 		return "HS384"
 	case AlgorithmHMAC512:
 		return "HS512"
+	case AlgorithmECDSA256:
+		return "ES256"
+	case AlgorithmECDSA384:
+		return "ES384"
+	case AlgorithmECDSA512:
+		return "ES512"
+	case AlgorithmRSAPSS256:
+		return "PS256"
+	case AlgorithmRSAPSS384:
+		return "PS384"
+	case AlgorithmRSAPSS512:
+		return "PS512"
+	case AlgorithmEdDSA:
+		return "EdDSA"
 	default:
 		return "Unknown"
 	}
 }
+
+// curve returns the elliptic curve which is required for the ECDSA variants.
+func (a Algorithm) curve() elliptic.Curve {
+	switch a {
+	case AlgorithmECDSA256:
+		return elliptic.P256()
+	case AlgorithmECDSA384:
+		return elliptic.P384()
+	case AlgorithmECDSA512:
+		return elliptic.P521()
+	default:
+		return nil
+	}
+}
+
+// checkKeyType verifies that the given public or private key
+// can be used with the algorithm.
+func (a Algorithm) checkKeyType(key interface{}) error {
+	var keyType string
+	var curve elliptic.Curve
+
+	switch k := key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		keyType = "RSA"
+	case *ecdsa.PublicKey:
+		keyType, curve = "ECDSA", k.Curve
+	case *ecdsa.PrivateKey:
+		keyType, curve = "ECDSA", k.Curve
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		keyType = "Ed25519"
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+
+	switch {
+	case a.IsRSA() && keyType == "RSA",
+		a == AlgorithmEdDSA && keyType == "Ed25519":
+		return nil
+	case a.IsECDSA() && keyType == "ECDSA":
+		if curve != a.curve() {
+			return fmt.Errorf("signature_algorithm %s requires an ECDSA key with curve %s, got %s",
+				a, a.curve().Params().Name, curve.Params().Name)
+		}
+		return nil
+	}
+
+	var expected string
+	switch {
+	case a.IsRSA():
+		expected = "RSA"
+	case a.IsECDSA():
+		expected = "ECDSA"
+	case a == AlgorithmEdDSA:
+		expected = "Ed25519"
+	}
+	return fmt.Errorf("signature_algorithm %s requires an %s key, got %s key", a, expected, keyType)
+}
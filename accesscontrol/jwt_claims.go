@@ -0,0 +1,136 @@
+package accesscontrol
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/internal/seetie"
+)
+
+// ClaimError describes a failed claim requirement of a token.
+type ClaimError struct {
+	Claim   string
+	Message string
+	Missing bool
+}
+
+func (e *ClaimError) Error() string {
+	return fmt.Sprintf("claim %q: %s", e.Claim, e.Message)
+}
+
+// evalClaims evaluates the claims expression with the context of the given request.
+// Evaluation errors are returned, to prevent missing requirements.
+func evalClaims(req *http.Request, expr hcl.Expression) (map[string]interface{}, error) {
+	var ctx *hcl.EvalContext
+	if evalCtx, ok := req.Context().Value(eval.ContextType).(*eval.Context); ok {
+		ctx = evalCtx.HCLContext()
+	}
+
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("claims: %s", diags.Error())
+	}
+
+	if !val.IsWhollyKnown() {
+		return nil, fmt.Errorf("claims: unknown value")
+	}
+
+	return seetie.ValueToMap(val), nil
+}
+
+// lookupClaim returns the token claim value of the given name.
+// Nested claims are referenced by a dot separated path, e.g. "realm_access.roles".
+func lookupClaim(claims map[string]interface{}, name string) (interface{}, bool) {
+	if v, exist := claims[name]; exist {
+		return v, true
+	}
+
+	var current interface{} = claims
+	for _, part := range strings.Split(name, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// matchClaim compares the expected value with the token value of a claim:
+//
+//	scalar expected, scalar value: equality
+//	scalar expected, list value:   the list contains the expected value
+//	list expected, scalar value:   the value is one of the expected values
+//	list expected, list value:     the list contains all expected values
+func matchClaim(expected, value interface{}) bool {
+	expList, expIsList := toList(expected)
+	valList, valIsList := toList(value)
+
+	switch {
+	case !expIsList && !valIsList:
+		return equalScalar(expected, value)
+	case !expIsList:
+		return contains(valList, expected)
+	case !valIsList:
+		return contains(expList, value)
+	default:
+		for _, e := range expList {
+			if !contains(valList, e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func toList(v interface{}) ([]interface{}, bool) {
+	switch l := v.(type) {
+	case []interface{}:
+		return l, true
+	case []string:
+		list := make([]interface{}, len(l))
+		for i, s := range l {
+			list[i] = s
+		}
+		return list, true
+	default:
+		return nil, false
+	}
+}
+
+func contains(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if equalScalar(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalScalar compares the values, list values of configured claims are strings only.
+func equalScalar(a, b interface{}) bool {
+	switch a.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+	switch b.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	}
+
+	if a == b {
+		return true
+	}
+
+	if a == nil || b == nil {
+		return false
+	}
+
+	return seetie.ToString(a) == seetie.ToString(b)
+}
@@ -0,0 +1,61 @@
+package accesscontrol
+
+import (
+	"crypto/ed25519"
+
+	"github.com/dgrijalva/jwt-go/v4"
+)
+
+// SigningMethodEdDSA implements the EdDSA signing method (RFC 8037) with Ed25519 keys
+// which is not provided by the jwt package.
+type SigningMethodEdDSA struct{}
+
+func init() {
+	if jwt.GetSigningMethod(AlgorithmEdDSA.String()) != nil {
+		return
+	}
+	jwt.RegisterSigningMethod(AlgorithmEdDSA.String(), func() jwt.SigningMethod {
+		return &SigningMethodEdDSA{}
+	})
+}
+
+// Alg implements the <jwt.SigningMethod> interface.
+func (m *SigningMethodEdDSA) Alg() string {
+	return AlgorithmEdDSA.String()
+}
+
+// Verify implements the <jwt.SigningMethod> interface and expects an <ed25519.PublicKey>.
+func (m *SigningMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	pubKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.NewInvalidKeyTypeError("ed25519.PublicKey", key)
+	}
+
+	if len(pubKey) != ed25519.PublicKeySize {
+		return &jwt.InvalidKeyError{Message: "invalid ed25519 public key size"}
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(pubKey, []byte(signingString), sig) {
+		return &jwt.InvalidSignatureError{}
+	}
+	return nil
+}
+
+// Sign implements the <jwt.SigningMethod> interface and expects an <ed25519.PrivateKey>.
+func (m *SigningMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.NewInvalidKeyTypeError("ed25519.PrivateKey", key)
+	}
+
+	if len(privKey) != ed25519.PrivateKeySize {
+		return "", &jwt.InvalidKeyError{Message: "invalid ed25519 private key size"}
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(privKey, []byte(signingString))), nil
+}
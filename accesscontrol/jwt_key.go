@@ -0,0 +1,73 @@
+package accesscontrol
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+
+	"github.com/dgrijalva/jwt-go/v4"
+)
+
+// parsePublicKey tries to parse all supported public key variations: PKCS1 (RSA only),
+// PKIX and certificates. The key must be given in PEM or DER format and can be base64 encoded.
+func parsePublicKey(key []byte) (interface{}, error) {
+	der := decodeKey(key)
+
+	if pubKey, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return pubKey, nil
+	}
+
+	if pubKey, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return pubKey, nil
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, ErrorNotSupported
+	}
+	return cert.PublicKey, nil
+}
+
+// parsePrivatePEMKey tries to parse the supported PKCS1 (RSA only), PKCS8 and
+// SEC1 (ECDSA only) private key variations which must be given in PEM encoded format.
+func parsePrivatePEMKey(key []byte) (interface{}, error) {
+	pemBlock, _ := pem.Decode(key)
+	if pemBlock == nil {
+		decKey, err := base64.StdEncoding.DecodeString(string(key))
+		if err != nil {
+			return nil, ErrorNotSupported
+		}
+		pemBlock, _ = pem.Decode(decKey)
+		if pemBlock == nil {
+			return nil, jwt.ErrKeyMustBePEMEncoded
+		}
+	}
+
+	if privKey, err := x509.ParsePKCS1PrivateKey(pemBlock.Bytes); err == nil {
+		return privKey, nil
+	}
+
+	if privKey, err := x509.ParseECPrivateKey(pemBlock.Bytes); err == nil {
+		return privKey, nil
+	}
+
+	return x509.ParsePKCS8PrivateKey(pemBlock.Bytes)
+}
+
+// decodeKey returns the DER bytes of the given PEM or DER key. Both variants
+// may be base64 encoded.
+func decodeKey(key []byte) []byte {
+	if pemBlock, _ := pem.Decode(key); pemBlock != nil {
+		return pemBlock.Bytes
+	}
+
+	decKey, err := base64.StdEncoding.DecodeString(string(key))
+	if err != nil {
+		return key
+	}
+
+	if pemBlock, _ := pem.Decode(decKey); pemBlock != nil {
+		return pemBlock.Bytes
+	}
+	return decKey
+}
@@ -0,0 +1,75 @@
+package accesscontrol
+
+import (
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	"github.com/avenga/couper/eval"
+)
+
+var _ eval.JWTSigner = &JWTSigner{}
+
+// JWTSigner creates tokens signed with the configured key.
+type JWTSigner struct {
+	algorithm  Algorithm
+	hmacSecret []byte
+	privKey    interface{}
+	ttl        time.Duration
+}
+
+// NewJWTSigner parses the private key or uses the HMAC secret for the given algorithm.
+func NewJWTSigner(algorithm string, key []byte, ttl time.Duration) (*JWTSigner, error) {
+	if len(key) == 0 {
+		return nil, ErrorMissingKey
+	}
+
+	algo := NewAlgorithm(algorithm)
+	if algo == AlgorithmUnknown {
+		return nil, ErrorNotSupported
+	}
+
+	signer := &JWTSigner{
+		algorithm: algo,
+		ttl:       ttl,
+	}
+
+	if algo.IsHMAC() {
+		signer.hmacSecret = key
+		return signer, nil
+	}
+
+	privKey, err := parsePrivatePEMKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = algo.checkKeyType(privKey); err != nil {
+		return nil, err
+	}
+
+	signer.privKey = privKey
+	return signer, nil
+}
+
+// Sign implements the <eval.JWTSigner> interface. The iat and exp claims
+// are set based on the configured ttl unless they are given.
+func (s *JWTSigner) Sign(claims map[string]interface{}) (string, error) {
+	mapClaims := jwt.MapClaims{}
+	now := time.Now()
+	mapClaims["iat"] = now.Unix()
+	if s.ttl > 0 {
+		mapClaims["exp"] = now.Add(s.ttl).Unix()
+	}
+
+	for k, v := range claims {
+		mapClaims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(s.algorithm.String()), mapClaims)
+
+	if s.algorithm.IsHMAC() {
+		return token.SignedString(s.hmacSecret)
+	}
+	return token.SignedString(s.privKey)
+}
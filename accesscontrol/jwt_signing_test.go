@@ -0,0 +1,115 @@
+package accesscontrol_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	ac "github.com/avenga/couper/accesscontrol"
+)
+
+func TestJWTSigner_Sign(t *testing.T) {
+	pubKeyBytes, privKey := newRSAKeyPair()
+
+	pkcs1 := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privKey),
+	})
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8 := pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: pkcs8Bytes,
+	})
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecKeyBytes, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1 := pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: ecKeyBytes,
+	})
+
+	edPubKey, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKeyBytes, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPKCS8 := pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: edKeyBytes,
+	})
+
+	tests := []struct {
+		name      string
+		algorithm string
+		signKey   []byte
+		verifyKey []byte
+		wantErr   bool
+	}{
+		{"HS256", "HS256", []byte("mySecretK3y"), []byte("mySecretK3y"), false},
+		{"RS256 PKCS1", "RS256", pkcs1, pubKeyBytes, false},
+		{"RS512 PKCS8", "RS512", pkcs8, pubKeyBytes, false},
+		{"RS256 base64 PEM", "RS256", []byte(base64.StdEncoding.EncodeToString(pkcs1)), pubKeyBytes, false},
+		{"PS256 PKCS1", "PS256", pkcs1, pubKeyBytes, false},
+		{"ES256 SEC1", "ES256", sec1, newPKIXPEM(&ecKey.PublicKey), false},
+		{"EdDSA PKCS8", "EdDSA", edPKCS8, newPKIXPEM(edPubKey), false},
+		{"RS256 public key", "RS256", pubKeyBytes, nil, true},
+		{"ES256 with RSA key", "ES256", pkcs1, nil, true},
+		{"EdDSA with ECDSA key", "EdDSA", sec1, nil, true},
+		{"unsupported algorithm", "none", []byte("mySecretK3y"), nil, true},
+		{"missing key", "HS256", nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			signer, err := ac.NewJWTSigner(tt.algorithm, tt.signKey, time.Minute)
+			if (err != nil) != tt.wantErr {
+				subT.Fatalf("NewJWTSigner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			token, err := signer.Sign(map[string]interface{}{"sub": "me"})
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			j, err := ac.NewJWT(tt.algorithm, "test", nil, []string{"exp", "iat"}, ac.Header, "Authorization", tt.verifyKey)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			if err = j.Validate(req); err != nil {
+				subT.Errorf("expected a valid token: %v", err)
+			}
+
+			claims := jwt.MapClaims{}
+			if _, _, err = new(jwt.Parser).ParseUnverified(token, claims); err != nil {
+				subT.Fatal(err)
+			}
+			if exp := claims["exp"].(float64) - claims["iat"].(float64); exp != 60 {
+				subT.Errorf("expected exp claim after ttl, got: %v", exp)
+			}
+		})
+	}
+}
@@ -1,19 +1,34 @@
 package accesscontrol_test
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 
 	ac "github.com/avenga/couper/accesscontrol"
 	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/eval"
 )
 
 func TestJWT_Validate(t *testing.T) {
@@ -149,6 +164,282 @@ func TestJWT_Validate(t *testing.T) {
 	}
 }
 
+func TestJWT_Validate_Params(t *testing.T) {
+	key := []byte("mySecretK3y")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "me"}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newFormRequest := func(method, body string, buffered bool) *http.Request {
+		req := httptest.NewRequest(method, "/?a=b", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if buffered {
+			req.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(strings.NewReader(body)), nil
+			}
+		}
+		return req
+	}
+
+	tests := []struct {
+		name      string
+		source    ac.Source
+		strip     bool
+		req       *http.Request
+		wantErr   bool
+		wantQuery string
+		wantBody  string
+	}{
+		{"query", ac.QueryParam, false, httptest.NewRequest(http.MethodGet, "/?a=b&token="+token, nil), false, "a=b&token=" + token, ""},
+		{"query /w strip", ac.QueryParam, true, httptest.NewRequest(http.MethodGet, "/?a=b&token="+token, nil), false, "a=b", ""},
+		{"query /w missing token", ac.QueryParam, false, httptest.NewRequest(http.MethodGet, "/?a=b", nil), true, "", ""},
+		{"query /w invalid token", ac.QueryParam, false, httptest.NewRequest(http.MethodGet, "/?token=abc", nil), true, "", ""},
+		{"post", ac.PostParam, false, newFormRequest(http.MethodPost, "c=d&token="+token, true), false, "a=b", "c=d&token=" + token},
+		{"post /w strip", ac.PostParam, true, newFormRequest(http.MethodPost, "c=d&token="+token, true), false, "a=b", "c=d"},
+		{"post /w unbuffered body", ac.PostParam, false, newFormRequest(http.MethodPost, "token="+token, false), true, "", ""},
+		{"post /w GET", ac.PostParam, false, newFormRequest(http.MethodGet, "token="+token, true), true, "", ""},
+		{"post /w token in query", ac.PostParam, false, newFormRequest(http.MethodPost, "c=d", true), true, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			j, err := ac.NewJWT("HS256", "test_ac", nil, nil, tt.source, "token", key)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			if tt.strip {
+				j.StripToken()
+			}
+
+			*tt.req = *tt.req.WithContext(eval.NewContext(nil).WithClientRequest(tt.req))
+			err = j.Validate(tt.req)
+			if (err != nil) != tt.wantErr {
+				subT.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if tt.req.URL.RawQuery != tt.wantQuery {
+				subT.Errorf("expected query %q, got: %q", tt.wantQuery, tt.req.URL.RawQuery)
+			}
+
+			if tt.wantBody != "" {
+				b, err := ioutil.ReadAll(tt.req.Body)
+				if err != nil {
+					subT.Fatal(err)
+				}
+				if string(b) != tt.wantBody {
+					subT.Errorf("expected body %q, got: %q", tt.wantBody, string(b))
+				}
+				if tt.req.ContentLength != int64(len(tt.wantBody)) {
+					subT.Errorf("expected content-length %d, got: %d", len(tt.wantBody), tt.req.ContentLength)
+				}
+			}
+
+			variable := eval.Query
+			if tt.source == ac.PostParam {
+				variable = eval.Post
+			}
+			evalCtx := tt.req.Context().Value(eval.ContextType).(*eval.Context)
+			values := evalCtx.HCLContext().Variables[eval.ClientRequest].GetAttr(variable)
+
+			var hasToken bool
+			if values.CanIterateElements() {
+				for it := values.ElementIterator(); it.Next(); {
+					if k, _ := it.Element(); k.AsString() == "token" {
+						hasToken = true
+					}
+				}
+			}
+			if hasToken == tt.strip {
+				subT.Errorf("expected req.%s.token: %v, got: %v", variable, !tt.strip, hasToken)
+			}
+		})
+	}
+}
+
+func TestJWT_Validate_Claims(t *testing.T) {
+	key := []byte("mySecretK3y")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"aud":    []string{"api", "web"},
+		"iss":    "couper",
+		"roles":  []string{"admin", "editor"},
+		"tenant": "acme",
+		"level":  3,
+		"realm_access": map[string]interface{}{
+			"roles": []string{"offline_access", "reader"},
+		},
+	}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		claims    string
+		wantClaim string
+	}{
+		{"aud in list", `{ aud = "api" }`, ""},
+		{"aud not in list", `{ aud = "other" }`, "aud"},
+		{"aud one of", `{ aud = ["api", "other"] }`, "aud"},
+		{"iss one of", `{ iss = ["couper", "other"] }`, ""},
+		{"iss", `{ iss = "other" }`, "iss"},
+		{"roles contains", `{ roles = "admin" }`, ""},
+		{"roles contains all", `{ roles = ["admin", "editor"] }`, ""},
+		{"roles missing", `{ roles = ["admin", "owner"] }`, "roles"},
+		{"number", `{ level = 3 }`, ""},
+		{"nested path", `{ "realm_access.roles" = "reader" }`, ""},
+		{"nested path mismatch", `{ "realm_access.roles" = "writer" }`, "realm_access.roles"},
+		{"nested path not found", `{ "realm_access.groups" = "reader" }`, "realm_access.groups"},
+		{"request variable", `{ tenant = req.path_params.tenant }`, ""},
+		{"request variable mismatch", `{ tenant = req.headers.x-tenant }`, "tenant"},
+		{"unknown request variable", `{ tenant = req.path_params.unknown }`, "claims"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(tt.claims), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				subT.Fatal(diags)
+			}
+
+			j, err := ac.NewJWT("HS256", "test_ac", nil, nil, ac.Header, "Authorization", key)
+			if err != nil {
+				subT.Fatal(err)
+			}
+			j.SetClaimsExpression(expr)
+
+			req := httptest.NewRequest(http.MethodGet, "/acme", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("X-Tenant", "other")
+			ctx := context.WithValue(req.Context(), request.PathParams, request.PathParameter{"tenant": "acme"})
+			req = req.WithContext(eval.NewContext(nil).WithClientRequest(req.WithContext(ctx)))
+
+			err = j.Validate(req)
+			switch {
+			case tt.wantClaim == "" && err != nil:
+				subT.Errorf("unexpected error: %v", err)
+			case tt.wantClaim == "claims":
+				if err == nil {
+					subT.Error("expected an evaluation error")
+				}
+			case tt.wantClaim != "":
+				var claimErr *ac.ClaimError
+				if !errors.As(err, &claimErr) {
+					subT.Fatalf("expected a claim error, got: %v", err)
+				}
+				if claimErr.Claim != tt.wantClaim {
+					subT.Errorf("expected failed claim %q, got: %q", tt.wantClaim, claimErr.Claim)
+				}
+			}
+		})
+	}
+}
+
+func TestJWT_Validate_KeyTypes(t *testing.T) {
+	_, rsaKey := newRSAKeyPair()
+	ec256Key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec384Key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	ec521Key, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	edPubKey, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		privKey   crypto.Signer
+		pubKey    []byte
+		wantErr   string
+	}{
+		{"ES256 PKIX", "ES256", ec256Key, newPKIXPEM(&ec256Key.PublicKey), ""},
+		{"ES384 PKIX", "ES384", ec384Key, newPKIXPEM(&ec384Key.PublicKey), ""},
+		{"ES512 PKIX", "ES512", ec521Key, newPKIXPEM(&ec521Key.PublicKey), ""},
+		{"ES256 certificate", "ES256", ec256Key, newCertificatePEM(ec256Key), ""},
+		{"ES256 DER certificate", "ES256", ec256Key, newCertificateDER(ec256Key), ""},
+		{"PS256 PKCS1", "PS256", rsaKey, newPKCS1PEM(&rsaKey.PublicKey), ""},
+		{"PS384 PKIX", "PS384", rsaKey, newPKIXPEM(&rsaKey.PublicKey), ""},
+		{"PS512 certificate", "PS512", rsaKey, newCertificatePEM(rsaKey), ""},
+		{"RS256 DER certificate", "RS256", rsaKey, newCertificateDER(rsaKey), ""},
+		{"EdDSA PKIX", "EdDSA", edKey, newPKIXPEM(edPubKey), ""},
+		{"EdDSA certificate", "EdDSA", edKey, newCertificatePEM(edKey), ""},
+		{"ES256 with RSA key", "ES256", nil, newPKIXPEM(&rsaKey.PublicKey),
+			"signature_algorithm ES256 requires an ECDSA key, got RSA key"},
+		{"ES256 with P-384 key", "ES256", nil, newPKIXPEM(&ec384Key.PublicKey),
+			"signature_algorithm ES256 requires an ECDSA key with curve P-256, got P-384"},
+		{"PS256 with ECDSA key", "PS256", nil, newPKIXPEM(&ec256Key.PublicKey),
+			"signature_algorithm PS256 requires an RSA key, got ECDSA key"},
+		{"EdDSA with RSA key", "EdDSA", nil, newPKCS1PEM(&rsaKey.PublicKey),
+			"signature_algorithm EdDSA requires an Ed25519 key, got RSA key"},
+		{"RS256 with Ed25519 key", "RS256", nil, newPKIXPEM(edPubKey),
+			"signature_algorithm RS256 requires an RSA key, got Ed25519 key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			j, err := ac.NewJWT(tt.algorithm, "test_ac", nil, nil, ac.Header, "Authorization", tt.pubKey)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					subT.Errorf("expected error %q, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			token, err := jwt.NewWithClaims(jwt.GetSigningMethod(tt.algorithm), jwt.MapClaims{"sub": "me"}).SignedString(tt.privKey)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			req := setCookieAndHeader(httptest.NewRequest(http.MethodGet, "/", nil), "Authorization", "Bearer "+token)
+			if err = j.Validate(req); err != nil {
+				subT.Errorf("expected a valid token: %v", err)
+			}
+
+			// tampered signature
+			req = setCookieAndHeader(httptest.NewRequest(http.MethodGet, "/", nil), "Authorization", "Bearer "+token[:len(token)-4]+"AAAA")
+			if err = j.Validate(req); err == nil {
+				subT.Error("expected an invalid signature error")
+			}
+		})
+	}
+}
+
+func newPKCS1PEM(pubKey *rsa.PublicKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(pubKey)})
+}
+
+func newPKIXPEM(pubKey crypto.PublicKey) []byte {
+	b, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b})
+}
+
+func newCertificateDER(privKey crypto.Signer) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "couper"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	b, err := x509.CreateCertificate(rand.Reader, template, template, privKey.Public(), privKey)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func newCertificatePEM(privKey crypto.Signer) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: newCertificateDER(privKey)})
+}
+
 func newRSAKeyPair() (pubKeyBytes []byte, privKey *rsa.PrivateKey) {
 	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
@@ -0,0 +1,438 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avenga/couper/config/request"
+)
+
+const (
+	// DefaultOIDCConfigurationTTL is the lifetime of a fetched OpenID configuration.
+	DefaultOIDCConfigurationTTL = time.Hour
+	// DefaultOIDCSessionTTL is the lifetime of a session cookie.
+	DefaultOIDCSessionTTL = time.Hour
+
+	oidcFlowTTL = time.Minute * 10
+)
+
+var (
+	ErrorOIDCSessionRequired = errors.New("oidc: session required")
+
+	_ AccessControl = &OIDC{}
+)
+
+// RedirectError requests a redirect of the client instead of a failure response, e.g. to
+// start a login at the authorization endpoint. The given cookies are set with the redirect.
+type RedirectError struct {
+	Cookies  []*http.Cookie
+	Location string
+	Message  string
+}
+
+func (e *RedirectError) Error() string {
+	return "redirect: " + e.Message
+}
+
+// OIDCOptions represents the OpenID Connect relying party configuration of an <OIDC> access control.
+// The Backend is used for the configuration, key set and token requests.
+type OIDCOptions struct {
+	Backend          http.RoundTripper
+	ClientID         string
+	ClientSecret     string
+	ConfigurationTTL time.Duration
+	ConfigurationURL string
+	Context          context.Context
+	CookieName       string
+	Name             string
+	RedirectURI      string
+	Scope            string
+	SessionSecret    []byte
+	SessionTTL       time.Duration
+}
+
+// OIDC runs the OpenID Connect authorization code flow with PKCE and validates
+// the resulting session cookie on subsequent requests.
+type OIDC struct {
+	cipher      *cookieCipher
+	options     *OIDCOptions
+	redirectURL *url.URL
+
+	mu        sync.Mutex
+	config    *oidcConfiguration
+	fetchedAt time.Time
+	jwt       *JWT
+}
+
+type oidcConfiguration struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	Issuer                string `json:"issuer"`
+	JWKSURI               string `json:"jwks_uri"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+type oidcFlow struct {
+	Nonce    string `json:"nonce"`
+	ReturnTo string `json:"return_to"`
+	State    string `json:"state"`
+	Verifier string `json:"verifier"`
+}
+
+type oidcSession struct {
+	Claims  map[string]interface{} `json:"claims"`
+	Expires int64                  `json:"exp"`
+}
+
+// NewOIDC creates a new <*OIDC> object. The OpenID configuration is fetched on demand.
+func NewOIDC(opts *OIDCOptions) (*OIDC, error) {
+	if opts.Backend == nil {
+		return nil, fmt.Errorf("oidc: missing backend")
+	}
+
+	if opts.ClientID == "" {
+		return nil, fmt.Errorf("oidc: missing client_id")
+	}
+
+	if u, err := url.Parse(opts.ConfigurationURL); err != nil || u.Host == "" {
+		return nil, fmt.Errorf("oidc: invalid configuration_url: %q", opts.ConfigurationURL)
+	}
+
+	// the client controlled host header must not determine the redirect_uri
+	redirectURL, err := url.Parse(opts.RedirectURI)
+	if err != nil || (redirectURL.Scheme != "http" && redirectURL.Scheme != "https") ||
+		redirectURL.Host == "" || !strings.HasPrefix(redirectURL.Path, "/") {
+		return nil, fmt.Errorf("oidc: invalid redirect_uri, an absolute http(s) URL is required: %q", opts.RedirectURI)
+	}
+
+	if len(opts.SessionSecret) < 16 {
+		return nil, fmt.Errorf("oidc: session_secret must have at least 16 characters")
+	}
+
+	if opts.CookieName == "" {
+		opts.CookieName = "couper_" + opts.Name
+	}
+
+	for _, name := range []string{opts.CookieName, opts.CookieName + "_flow"} {
+		if (&http.Cookie{Name: name, Value: "v"}).String() == "" {
+			return nil, fmt.Errorf("oidc: invalid cookie_name: %q", name)
+		}
+	}
+
+	if opts.Context == nil {
+		opts.Context = context.Background()
+	}
+
+	if opts.ConfigurationTTL <= 0 {
+		opts.ConfigurationTTL = DefaultOIDCConfigurationTTL
+	}
+
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = DefaultOIDCSessionTTL
+	}
+
+	scopes := strings.Fields(opts.Scope)
+	if !containsString(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	opts.Scope = strings.Join(scopes, " ")
+
+	c, err := newCookieCipher(opts.SessionSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDC{cipher: c, options: opts, redirectURL: redirectURL}, nil
+}
+
+// Validate implements the <AccessControl> interface. Requests without a valid session
+// are redirected to the authorization endpoint, requests to the redirect_uri path
+// complete the login with the code exchange.
+func (o *OIDC) Validate(req *http.Request) error {
+	query := req.URL.Query()
+	if req.URL.Path == o.redirectURL.Path && (query.Get("code") != "" || query.Get("error") != "") {
+		return o.callback(req)
+	}
+
+	if cookie, err := req.Cookie(o.options.CookieName); err == nil {
+		session := &oidcSession{}
+		if err = o.cipher.open(o.options.CookieName, cookie.Value, session); err == nil &&
+			time.Now().Unix() < session.Expires {
+			setAccessControlContext(req, o.options.Name, session.Claims)
+			return nil
+		}
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return ErrorOIDCSessionRequired
+	}
+
+	return o.login(req)
+}
+
+func (o *OIDC) login(req *http.Request) error {
+	conf, _, err := o.configuration()
+	if err != nil {
+		return err
+	}
+
+	flow := &oidcFlow{ReturnTo: localPath(req.URL.RequestURI())}
+	for _, v := range []*string{&flow.Nonce, &flow.State, &flow.Verifier} {
+		if *v, err = randomString(32); err != nil {
+			return err
+		}
+	}
+
+	flowName := o.options.CookieName + "_flow"
+	value, err := o.cipher.seal(flowName, flow)
+	if err != nil {
+		return err
+	}
+
+	authURL, err := url.Parse(conf.AuthorizationEndpoint)
+	if err != nil {
+		return fmt.Errorf("oidc: invalid authorization_endpoint: %v", err)
+	}
+
+	challenge := sha256.Sum256([]byte(flow.Verifier))
+
+	query := authURL.Query()
+	query.Set("client_id", o.options.ClientID)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	query.Set("nonce", flow.Nonce)
+	query.Set("redirect_uri", o.redirectURL.String())
+	query.Set("response_type", "code")
+	query.Set("scope", o.options.Scope)
+	query.Set("state", flow.State)
+	authURL.RawQuery = query.Encode()
+
+	return &RedirectError{
+		Cookies:  []*http.Cookie{o.newCookie(req, flowName, value, oidcFlowTTL)},
+		Location: authURL.String(),
+		Message:  "oidc: login required",
+	}
+}
+
+func (o *OIDC) callback(req *http.Request) error {
+	query := req.URL.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		return fmt.Errorf("oidc: authorization error: %s: %s", errCode, query.Get("error_description"))
+	}
+
+	flowName := o.options.CookieName + "_flow"
+	cookie, err := req.Cookie(flowName)
+	if err != nil {
+		return fmt.Errorf("oidc: missing login flow cookie")
+	}
+
+	flow := &oidcFlow{}
+	if err = o.cipher.open(flowName, cookie.Value, flow); err != nil {
+		return fmt.Errorf("oidc: login flow cookie: %v", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(flow.State)) != 1 {
+		return fmt.Errorf("oidc: state mismatch")
+	}
+
+	conf, idTokenValidator, err := o.configuration()
+	if err != nil {
+		return err
+	}
+
+	idToken, err := o.exchangeCode(req, conf, query.Get("code"), flow.Verifier)
+	if err != nil {
+		return err
+	}
+
+	claims, err := idTokenValidator.validateToken(req, idToken)
+	if err != nil {
+		return fmt.Errorf("oidc: id_token: %w", err)
+	}
+
+	if nonce, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(nonce), []byte(flow.Nonce)) != 1 {
+		return &ClaimError{Claim: "nonce", Message: "unexpected value"}
+	}
+
+	session := &oidcSession{
+		Claims:  claims,
+		Expires: time.Now().Add(o.options.SessionTTL).Unix(),
+	}
+	value, err := o.cipher.seal(o.options.CookieName, session)
+	if err != nil {
+		return err
+	}
+
+	return &RedirectError{
+		Cookies: []*http.Cookie{
+			o.newCookie(req, o.options.CookieName, value, o.options.SessionTTL),
+			o.newCookie(req, flowName, "", -1),
+		},
+		Location: localPath(flow.ReturnTo),
+		Message:  "oidc: login succeeded",
+	}
+}
+
+// configuration returns the cached OpenID configuration and the related id token validator.
+// A failed refresh keeps the previous configuration in use.
+func (o *OIDC) configuration() (*oidcConfiguration, *JWT, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.config != nil && time.Since(o.fetchedAt) < o.options.ConfigurationTTL {
+		return o.config, o.jwt, nil
+	}
+
+	conf, err := o.fetchConfiguration()
+	if err != nil {
+		if o.config != nil {
+			o.fetchedAt = time.Now()
+			return o.config, o.jwt, nil
+		}
+		return nil, nil, fmt.Errorf("oidc: configuration unavailable: %v", err)
+	}
+
+	if o.config == nil || o.config.Issuer != conf.Issuer || o.config.JWKSURI != conf.JWKSURI {
+		jwks, jerr := NewJWKS(&JWKSOptions{
+			Backend: o.options.Backend,
+			Context: o.options.Context,
+			URL:     conf.JWKSURI,
+		})
+		if jerr != nil {
+			return nil, nil, jerr
+		}
+
+		claims := map[string]interface{}{"aud": o.options.ClientID, "iss": conf.Issuer}
+		o.jwt, err = NewJWTFromJWKS("", o.options.Name, claims, []string{"exp", "sub"}, Header, "Authorization", jwks)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	o.config = conf
+	o.fetchedAt = time.Now()
+	return o.config, o.jwt, nil
+}
+
+func (o *OIDC) fetchConfiguration() (*oidcConfiguration, error) {
+	ctx := context.WithValue(o.options.Context, request.RoundTripName, "oidc")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.options.ConfigurationURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	body, err := o.roundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &oidcConfiguration{}
+	if err = json.Unmarshal(body, conf); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %v", err)
+	}
+
+	if conf.AuthorizationEndpoint == "" || conf.Issuer == "" || conf.JWKSURI == "" || conf.TokenEndpoint == "" {
+		return nil, fmt.Errorf("configuration requires authorization_endpoint, issuer, jwks_uri and token_endpoint")
+	}
+	return conf, nil
+}
+
+// exchangeCode requests the tokens for the given authorization code and returns the id token.
+func (o *OIDC) exchangeCode(req *http.Request, conf *oidcConfiguration, code, verifier string) (string, error) {
+	form := url.Values{}
+	form.Set("code", code)
+	form.Set("code_verifier", verifier)
+	form.Set("grant_type", "authorization_code")
+	form.Set("redirect_uri", o.redirectURL.String())
+	if o.options.ClientSecret == "" {
+		form.Set("client_id", o.options.ClientID)
+	}
+
+	ctx := context.WithValue(req.Context(), request.RoundTripName, "oidc")
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPost, conf.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	tokenReq.Header.Set("Accept", "application/json")
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if o.options.ClientSecret != "" {
+		// RFC 6749, section 2.3.1
+		tokenReq.SetBasicAuth(url.QueryEscape(o.options.ClientID), url.QueryEscape(o.options.ClientSecret))
+	}
+
+	body, err := o.roundTrip(tokenReq)
+	if err != nil {
+		return "", fmt.Errorf("oidc: token request: %v", err)
+	}
+
+	tr := &struct {
+		IDToken string `json:"id_token"`
+	}{}
+	if err = json.Unmarshal(body, tr); err != nil {
+		return "", fmt.Errorf("oidc: invalid token response: %v", err)
+	}
+
+	if tr.IDToken == "" {
+		return "", fmt.Errorf("oidc: missing id_token in token response")
+	}
+	return tr.IDToken, nil
+}
+
+func (o *OIDC) roundTrip(req *http.Request) ([]byte, error) {
+	res, err := o.options.Backend.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", res.StatusCode)
+	}
+	return body, nil
+}
+
+// localPath returns the given request URI if it is a path of this host, "/" otherwise.
+// Browsers interpret paths like "//host/path" or "/\host/path" as another host.
+func localPath(uri string) string {
+	if !strings.HasPrefix(uri, "/") || strings.HasPrefix(uri, "//") || strings.HasPrefix(uri, "/\\") {
+		return "/"
+	}
+	return uri
+}
+
+// newCookie creates a session related cookie, a negative ttl deletes the cookie. The cookie
+// is secure with a https redirect_uri or for requests via TLS.
+func (o *OIDC) newCookie(req *http.Request, name, value string, ttl time.Duration) *http.Cookie {
+	maxAge := int(ttl.Seconds())
+	if ttl < 0 {
+		maxAge = -1
+	}
+
+	return &http.Cookie{
+		HttpOnly: true,
+		MaxAge:   maxAge,
+		Name:     name,
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+		Secure:   o.redirectURL.Scheme == "https" || req.TLS != nil,
+		Value:    value,
+	}
+}
@@ -0,0 +1,80 @@
+package accesscontrol
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+var errInvalidCookie = errors.New("invalid cookie value")
+
+// cookieCipher encrypts and authenticates cookie values with AES-GCM. The key is
+// derived from the configured secret.
+type cookieCipher struct {
+	aead cipher.AEAD
+}
+
+func newCookieCipher(secret []byte) (*cookieCipher, error) {
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &cookieCipher{aead: aead}, nil
+}
+
+// seal returns the encrypted JSON representation of the given value. The cookie name
+// is used as additional data to prevent the exchange of cookie values.
+func (c *cookieCipher) seal(name string, v interface{}) (string, error) {
+	plain, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, plain, []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts the given cookie value into v.
+func (c *cookieCipher) open(name, value string, v interface{}) error {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return errInvalidCookie
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return errInvalidCookie
+	}
+
+	plain, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(name))
+	if err != nil {
+		return errInvalidCookie
+	}
+
+	return json.Unmarshal(plain, v)
+}
+
+// randomString returns a base64url encoded random value of the given byte length.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,285 @@
+package accesscontrol_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config/request"
+)
+
+type testIdP struct {
+	*httptest.Server
+	mu    sync.Mutex
+	codes map[string]url.Values
+}
+
+// newTestIdP creates a mock OpenID provider which issues codes for the authorization
+// requests passed to authorize.
+func newTestIdP(t *testing.T) *testIdP {
+	_, key := newRSAKeyPair()
+	idp := &testIdP{codes: make(map[string]url.Values)}
+
+	idp.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(rw).Encode(map[string]string{
+				"authorization_endpoint": idp.URL + "/authorize",
+				"issuer":                 idp.URL,
+				"jwks_uri":               idp.URL + "/jwks",
+				"token_endpoint":         idp.URL + "/token",
+			})
+		case "/jwks":
+			_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+				"keys": []map[string]interface{}{newRSAJWK("idp", key)},
+			})
+		case "/token":
+			clientID, clientSecret, _ := req.BasicAuth()
+			_ = req.ParseForm()
+
+			idp.mu.Lock()
+			authReq, exist := idp.codes[req.PostForm.Get("code")]
+			delete(idp.codes, req.PostForm.Get("code"))
+			idp.mu.Unlock()
+
+			challenge := sha256.Sum256([]byte(req.PostForm.Get("code_verifier")))
+			if !exist || clientID != "client" || clientSecret != "secret" ||
+				authReq.Get("redirect_uri") != req.PostForm.Get("redirect_uri") ||
+				authReq.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+				rw.WriteHeader(http.StatusBadRequest)
+				_, _ = rw.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+
+			tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+				"aud":   "client",
+				"exp":   time.Now().Add(time.Minute).Unix(),
+				"iss":   idp.URL,
+				"name":  "John Doe",
+				"nonce": authReq.Get("nonce"),
+				"sub":   "john",
+			})
+			tok.Header["kid"] = "idp"
+			idToken, err := tok.SignedString(key)
+			if err != nil {
+				t.Error(err)
+			}
+			_ = json.NewEncoder(rw).Encode(map[string]string{"id_token": idToken})
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return idp
+}
+
+// authorize simulates the user login at the authorization endpoint and returns the code.
+func (idp *testIdP) authorize(t *testing.T, location string) (code, state string) {
+	u, err := url.Parse(location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("response_type") != "code" ||
+		!strings.Contains(query.Get("scope"), "openid") {
+		t.Fatalf("unexpected authorization request: %s", location)
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	code = "code" + query.Get("state")[:8]
+	idp.codes[code] = query
+	return code, query.Get("state")
+}
+
+func TestOIDC_Validate(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.Close()
+
+	oidc, err := ac.NewOIDC(&ac.OIDCOptions{
+		Backend:          http.DefaultTransport,
+		ClientID:         "client",
+		ClientSecret:     "secret",
+		ConfigurationURL: idp.URL + "/.well-known/openid-configuration",
+		Name:             "login",
+		RedirectURI:      "http://couper.local/oidc/callback",
+		Scope:            "profile",
+		SessionSecret:    []byte("s3cr3t-s3ss10n-k3y"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newRequest := func(method, target string, cookies ...*http.Cookie) *http.Request {
+		req := httptest.NewRequest(method, "http://couper.local"+target, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		return req
+	}
+
+	redirectErr := func(err error) *ac.RedirectError {
+		redirect, ok := err.(*ac.RedirectError)
+		if !ok {
+			t.Fatalf("expected a redirect, got: %v", err)
+		}
+		return redirect
+	}
+
+	// start login
+	redirect := redirectErr(oidc.Validate(newRequest(http.MethodGet, "/app?a=b")))
+	if !strings.HasPrefix(redirect.Location, idp.URL+"/authorize?") {
+		t.Fatalf("expected a redirect to the authorization endpoint, got: %s", redirect.Location)
+	}
+	if len(redirect.Cookies) != 1 || redirect.Cookies[0].Name != "couper_login_flow" {
+		t.Fatalf("expected the flow cookie, got: %v", redirect.Cookies)
+	}
+	flowCookie := redirect.Cookies[0]
+	if flowCookie.Secure {
+		t.Error("expected an insecure flow cookie for a http redirect_uri")
+	}
+
+	code, state := idp.authorize(t, redirect.Location)
+
+	// callback with a wrong state
+	err = oidc.Validate(newRequest(http.MethodGet, "/oidc/callback?code="+code+"&state=other", flowCookie))
+	if _, ok := err.(*ac.RedirectError); ok || err == nil {
+		t.Errorf("expected a state error, got: %v", err)
+	}
+
+	// callback without the flow cookie
+	err = oidc.Validate(newRequest(http.MethodGet, "/oidc/callback?code="+code+"&state="+state))
+	if _, ok := err.(*ac.RedirectError); ok || err == nil {
+		t.Errorf("expected a missing flow cookie error, got: %v", err)
+	}
+
+	// authorization error
+	err = oidc.Validate(newRequest(http.MethodGet, "/oidc/callback?error=access_denied&state="+state, flowCookie))
+	if _, ok := err.(*ac.RedirectError); ok || err == nil {
+		t.Errorf("expected an authorization error, got: %v", err)
+	}
+
+	redirect = redirectErr(oidc.Validate(newRequest(http.MethodGet, "/oidc/callback?code="+code+"&state="+state, flowCookie)))
+	if redirect.Location != "/app?a=b" {
+		t.Errorf("expected a redirect to the initial request, got: %s", redirect.Location)
+	}
+
+	var sessionCookie *http.Cookie
+	for _, c := range redirect.Cookies {
+		switch c.Name {
+		case "couper_login":
+			sessionCookie = c
+		case "couper_login_flow":
+			if c.MaxAge >= 0 {
+				t.Error("expected the deletion of the flow cookie")
+			}
+		}
+	}
+	if sessionCookie == nil || !sessionCookie.HttpOnly {
+		t.Fatalf("expected a http only session cookie, got: %v", redirect.Cookies)
+	}
+
+	// the code can be used once
+	err = oidc.Validate(newRequest(http.MethodGet, "/oidc/callback?code="+code+"&state="+state, flowCookie))
+	if _, ok := err.(*ac.RedirectError); ok || err == nil {
+		t.Errorf("expected a token request error, got: %v", err)
+	}
+
+	req := newRequest(http.MethodPost, "/app", sessionCookie)
+	if err = oidc.Validate(req); err != nil {
+		t.Fatal(err)
+	}
+
+	acMap, _ := req.Context().Value(request.AccessControls).(map[string]interface{})
+	claims, _ := acMap["login"].(map[string]interface{})
+	if claims["sub"] != "john" || claims["name"] != "John Doe" {
+		t.Errorf("expected the id token claims, got: %v", claims)
+	}
+
+	tamperedValue := "A" + sessionCookie.Value[1:]
+	if tamperedValue == sessionCookie.Value {
+		tamperedValue = "B" + sessionCookie.Value[1:]
+	}
+	tampered := &http.Cookie{Name: sessionCookie.Name, Value: tamperedValue}
+	redirectErr(oidc.Validate(newRequest(http.MethodGet, "/app", tampered)))
+
+	if err = oidc.Validate(newRequest(http.MethodPost, "/app", tampered)); err != ac.ErrorOIDCSessionRequired {
+		t.Errorf("expected a session required error, got: %v", err)
+	}
+}
+
+func TestOIDC_Validate_LocalRedirect(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.Close()
+
+	oidc, err := ac.NewOIDC(&ac.OIDCOptions{
+		Backend:          http.DefaultTransport,
+		ClientID:         "client",
+		ClientSecret:     "secret",
+		ConfigurationURL: idp.URL + "/.well-known/openid-configuration",
+		Name:             "login",
+		RedirectURI:      "https://couper.local/oidc/callback",
+		SessionSecret:    []byte("s3cr3t-s3ss10n-k3y"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{"//evil.example/x", "///evil.example/x"} {
+		t.Run(target, func(subT *testing.T) {
+			redirect, ok := oidc.Validate(httptest.NewRequest(http.MethodGet, "http://couper.local"+target, nil)).(*ac.RedirectError)
+			if !ok || len(redirect.Cookies) != 1 {
+				subT.Fatalf("expected a redirect with the flow cookie, got: %v", redirect)
+			}
+
+			flowCookie := redirect.Cookies[0]
+			if !flowCookie.Secure {
+				subT.Error("expected a secure flow cookie for a https redirect_uri")
+			}
+
+			code, state := idp.authorize(subT, redirect.Location)
+			req := httptest.NewRequest(http.MethodGet, "http://couper.local/oidc/callback?code="+code+"&state="+state, nil)
+			req.AddCookie(flowCookie)
+
+			redirect, ok = oidc.Validate(req).(*ac.RedirectError)
+			if !ok {
+				subT.Fatalf("expected a redirect, got: %v", redirect)
+			}
+			if redirect.Location != "/" {
+				subT.Errorf("expected a redirect to the local root path, got: %s", redirect.Location)
+			}
+		})
+	}
+}
+
+func TestNewOIDC_RedirectURI(t *testing.T) {
+	for _, redirectURI := range []string{"/oidc/callback", "//couper.local/oidc/callback", "ftp://couper.local/oidc/callback", "https:///oidc/callback"} {
+		t.Run(redirectURI, func(subT *testing.T) {
+			_, err := ac.NewOIDC(&ac.OIDCOptions{
+				Backend:          http.DefaultTransport,
+				ClientID:         "client",
+				ConfigurationURL: "https://idp.example.com/.well-known/openid-configuration",
+				Name:             "login",
+				RedirectURI:      redirectURI,
+				SessionSecret:    []byte("s3cr3t-s3ss10n-k3y"),
+			})
+
+			wantErr := fmt.Sprintf("oidc: invalid redirect_uri, an absolute http(s) URL is required: %q", redirectURI)
+			if err == nil || err.Error() != wantErr {
+				subT.Errorf("expected error %q, got: %v", wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package accesscontrol
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/internal/seetie"
+	"github.com/avenga/couper/utils"
+)
+
+const (
+	RateLimitSlidingWindow = "sliding_window"
+	RateLimitTokenBucket   = "token_bucket"
+
+	// DefaultRateLimitMaxKeys is the default number of tracked keys per <RateLimit>.
+	DefaultRateLimitMaxKeys = 10000
+)
+
+var _ AccessControl = &RateLimit{}
+
+// RateLimitError describes a request which exceeds the limit of a <RateLimit>.
+type RateLimitError struct {
+	Limit      int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate_limit: limit of %d requests exceeded, retry after %s", e.Limit, e.RetryAfter)
+}
+
+// RateLimitOptions represents the configuration of a <RateLimit> access control.
+// The Key expression defaults to the client address.
+type RateLimitOptions struct {
+	Algorithm string
+	Key       hcl.Expression
+	Limit     int
+	Log       *logrus.Entry
+	MaxKeys   int
+	Name      string
+	Window    time.Duration
+}
+
+// RateLimit limits the number of requests per key within the configured window. The state
+// is kept in memory, the least recently used keys are dropped once MaxKeys is exceeded.
+type RateLimit struct {
+	algorithm string
+	keyExpr   hcl.Expression
+	limit     int
+	log       *logrus.Entry
+	maxKeys   int
+	name      string
+	window    time.Duration
+
+	fallbackOnce sync.Once
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	now     func() time.Time
+}
+
+type rateLimitEntry struct {
+	key string
+
+	// sliding window
+	current, previous int
+	windowStart       time.Time
+
+	// token bucket
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewRateLimit creates a new <*RateLimit> object.
+func NewRateLimit(opts *RateLimitOptions) (*RateLimit, error) {
+	algorithm := opts.Algorithm
+	if algorithm == "" {
+		algorithm = RateLimitSlidingWindow
+	}
+
+	if algorithm != RateLimitSlidingWindow && algorithm != RateLimitTokenBucket {
+		return nil, fmt.Errorf("rate_limit: unsupported algorithm: %q", opts.Algorithm)
+	}
+
+	if opts.Limit < 1 {
+		return nil, fmt.Errorf("rate_limit: limit must be greater than 0")
+	}
+
+	if opts.Window <= 0 {
+		return nil, fmt.Errorf("rate_limit: window must be greater than 0")
+	}
+
+	maxKeys := opts.MaxKeys
+	if maxKeys == 0 {
+		maxKeys = DefaultRateLimitMaxKeys
+	} else if maxKeys < 0 {
+		return nil, fmt.Errorf("rate_limit: max_keys must be greater than 0")
+	}
+
+	return &RateLimit{
+		algorithm: algorithm,
+		keyExpr:   opts.Key,
+		limit:     opts.Limit,
+		log:       opts.Log,
+		maxKeys:   maxKeys,
+		name:      opts.Name,
+		window:    opts.Window,
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+		now:       time.Now,
+	}, nil
+}
+
+// Validate implements the <AccessControl> interface.
+func (r *RateLimit) Validate(req *http.Request) error {
+	key := r.key(req)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := r.entry(key)
+	if r.algorithm == RateLimitTokenBucket {
+		return r.takeToken(entry)
+	}
+	return r.countRequest(entry)
+}
+
+// ClientKeyed reports whether the requests are limited per client address.
+func (r *RateLimit) ClientKeyed() bool {
+	return r.keyExpr == nil
+}
+
+// key evaluates the key expression with the context of the given request. Keys which
+// fail to evaluate, e.g. a missing header, or are empty fall back to the client address.
+func (r *RateLimit) key(req *http.Request) string {
+	if r.keyExpr == nil {
+		return utils.ClientIP(req)
+	}
+
+	var ctx *hcl.EvalContext
+	if evalCtx, ok := req.Context().Value(eval.ContextType).(*eval.Context); ok {
+		ctx = evalCtx.HCLContext()
+	}
+
+	val, diags := r.keyExpr.Value(ctx)
+	if diags.HasErrors() {
+		r.fallback(req, diags)
+		return utils.ClientIP(req)
+	}
+
+	key := seetie.ValueToString(val)
+	if key == "" {
+		r.fallback(req, fmt.Errorf("empty key"))
+		return utils.ClientIP(req)
+	}
+	return key
+}
+
+// fallback records the rate limit name for the access log of the given request. The
+// warning is logged once, keys may be absent for most requests, e.g. of anonymous clients.
+func (r *RateLimit) fallback(req *http.Request, err error) {
+	r.fallbackOnce.Do(func() {
+		if r.log != nil {
+			r.log.WithError(err).Warn("rate_limit: key falls back to the client address")
+		}
+	})
+
+	names, _ := req.Context().Value(request.RateLimitFallback).([]string)
+	names = append(names[:len(names):len(names)], r.name)
+	*req = *req.WithContext(context.WithValue(req.Context(), request.RateLimitFallback, names))
+}
+
+// entry returns the state of the given key and marks it as recently used.
+func (r *RateLimit) entry(key string) *rateLimitEntry {
+	if elem, exist := r.entries[key]; exist {
+		r.lru.MoveToFront(elem)
+		return elem.Value.(*rateLimitEntry)
+	}
+
+	if r.lru.Len() >= r.maxKeys {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.entries, oldest.Value.(*rateLimitEntry).key)
+	}
+
+	now := r.now()
+	entry := &rateLimitEntry{
+		key:         key,
+		tokens:      float64(r.limit),
+		updatedAt:   now,
+		windowStart: now.Truncate(r.window),
+	}
+	r.entries[key] = r.lru.PushFront(entry)
+	return entry
+}
+
+// countRequest approximates a sliding window by weighting the count of the previous
+// fixed window with its remaining overlap.
+func (r *RateLimit) countRequest(entry *rateLimitEntry) error {
+	now := r.now()
+	windowStart := now.Truncate(r.window)
+
+	switch elapsed := windowStart.Sub(entry.windowStart); {
+	case elapsed >= 2*r.window:
+		entry.previous, entry.current = 0, 0
+	case elapsed >= r.window:
+		entry.previous, entry.current = entry.current, 0
+	}
+	entry.windowStart = windowStart
+
+	reset := windowStart.Add(r.window).Sub(now)
+	weight := float64(reset) / float64(r.window)
+	count := int(math.Floor(float64(entry.previous)*weight)) + entry.current
+
+	if count >= r.limit {
+		retryAfter := reset
+		if entry.current < r.limit && entry.previous > 0 {
+			// the weighted count of the previous window drops below the limit before the reset
+			remaining := float64(r.window) * float64(r.limit-entry.current) / float64(entry.previous)
+			retryAfter = reset - time.Duration(math.Floor(remaining))
+		}
+		return &RateLimitError{Limit: r.limit, Reset: reset, RetryAfter: retryAfter}
+	}
+
+	entry.current++
+	return nil
+}
+
+// takeToken refills the bucket of the given entry by limit tokens per window and takes one.
+func (r *RateLimit) takeToken(entry *rateLimitEntry) error {
+	now := r.now()
+	rate := float64(r.limit) / float64(r.window)
+
+	entry.tokens = math.Min(float64(r.limit), entry.tokens+float64(now.Sub(entry.updatedAt))*rate)
+	entry.updatedAt = now
+
+	if entry.tokens < 1 {
+		retryAfter := time.Duration(math.Ceil((1 - entry.tokens) / rate))
+		reset := time.Duration(math.Ceil((float64(r.limit) - entry.tokens) / rate))
+		return &RateLimitError{Limit: r.limit, Reset: reset, RetryAfter: retryAfter}
+	}
+
+	entry.tokens--
+	return nil
+}
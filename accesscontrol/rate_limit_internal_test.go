@@ -0,0 +1,187 @@
+package accesscontrol
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/avenga/couper/config/request"
+)
+
+func Test_RateLimit(t *testing.T) {
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	type step struct {
+		offset    time.Duration
+		addr      string
+		wantErr   bool
+		wantRetry time.Duration
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		maxKeys   int
+		steps     []step
+	}{
+		{RateLimitSlidingWindow, "", 0, []step{
+			{0, "1.2.3.4", false, 0},
+			{time.Second, "1.2.3.4", false, 0},
+			{time.Second * 2, "1.2.3.4", true, time.Second * 8},
+			{time.Second * 2, "5.6.7.8", false, 0},
+			// previous window weighted with 0.9
+			{time.Second * 11, "1.2.3.4", false, 0},
+			{time.Second * 12, "1.2.3.4", true, time.Second * 3},
+			// previous window weighted with 0.4
+			{time.Second * 16, "1.2.3.4", false, 0},
+			{time.Second * 17, "1.2.3.4", true, time.Second * 3},
+			{time.Second * 40, "1.2.3.4", false, 0},
+		}},
+		{RateLimitTokenBucket, RateLimitTokenBucket, 0, []step{
+			{0, "1.2.3.4", false, 0},
+			{0, "1.2.3.4", false, 0},
+			{0, "1.2.3.4", true, time.Second * 5},
+			{time.Second * 2, "1.2.3.4", true, time.Second * 3},
+			{time.Second * 6, "1.2.3.4", false, 0},
+			{time.Second * 6, "1.2.3.4", true, time.Second * 4},
+			{time.Second * 20, "1.2.3.4", false, 0},
+			{time.Second * 20, "1.2.3.4", false, 0},
+		}},
+		{"max_keys", "", 1, []step{
+			{0, "1.2.3.4", false, 0},
+			{0, "1.2.3.4", false, 0},
+			{0, "5.6.7.8", false, 0},
+			// dropped
+			{0, "1.2.3.4", false, 0},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			rl, err := NewRateLimit(&RateLimitOptions{
+				Algorithm: tt.algorithm,
+				Limit:     2,
+				MaxKeys:   tt.maxKeys,
+				Window:    time.Second * 10,
+			})
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			for i, s := range tt.steps {
+				rl.now = func() time.Time { return start.Add(s.offset) }
+
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = s.addr + ":12345"
+
+				err = rl.Validate(req)
+				if (err != nil) != s.wantErr {
+					subT.Fatalf("step %d: expected error: %v, got: %v", i, s.wantErr, err)
+				}
+
+				if !s.wantErr {
+					continue
+				}
+
+				var rlErr *RateLimitError
+				if !errors.As(err, &rlErr) {
+					subT.Fatalf("step %d: expected a *RateLimitError, got: %T", i, err)
+				}
+
+				if retry := rlErr.RetryAfter.Round(time.Millisecond); retry != s.wantRetry {
+					subT.Errorf("step %d: expected retry after %s, got: %s", i, s.wantRetry, retry)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRateLimit_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *RateLimitOptions
+		wantErr string
+	}{
+		{"algorithm", &RateLimitOptions{Algorithm: "leaky_bucket", Limit: 1, Window: time.Second}, `rate_limit: unsupported algorithm: "leaky_bucket"`},
+		{"limit", &RateLimitOptions{Window: time.Second}, "rate_limit: limit must be greater than 0"},
+		{"window", &RateLimitOptions{Limit: 1}, "rate_limit: window must be greater than 0"},
+		{"max_keys", &RateLimitOptions{Limit: 1, MaxKeys: -1, Window: time.Second}, "rate_limit: max_keys must be greater than 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := NewRateLimit(tt.opts)
+			if err == nil || err.Error() != tt.wantErr {
+				subT.Errorf("expected error %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_RateLimit_KeyFallback(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{"evaluation error", "req.headers.x-key", "Variables not allowed"},
+		{"empty key", `""`, "empty key"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			keyExpr, diags := hclsyntax.ParseExpression([]byte(tc.expr), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				subT.Fatal(diags)
+			}
+
+			logger, hook := logrustest.NewNullLogger()
+			rl, err := NewRateLimit(&RateLimitOptions{
+				Key:    keyExpr,
+				Limit:  1,
+				Log:    logrus.NewEntry(logger),
+				Name:   "PerKey",
+				Window: time.Minute,
+			})
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			if rl.ClientKeyed() {
+				subT.Error("expected a keyed rate limit")
+			}
+
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				if key := rl.key(req); key != "192.0.2.1" {
+					subT.Errorf("expected the client address, got: %q", key)
+				}
+
+				if names, _ := req.Context().Value(request.RateLimitFallback).([]string); !reflect.DeepEqual(names, []string{"PerKey"}) {
+					subT.Errorf("expected the fallback for the access log, got: %v", names)
+				}
+			}
+
+			// logged once per rate limit
+			if entries := hook.AllEntries(); len(entries) != 1 {
+				subT.Fatalf("expected one logged fallback, got: %d", len(entries))
+			}
+
+			entry := hook.LastEntry()
+			if entry.Level != logrus.WarnLevel {
+				subT.Fatalf("expected a logged warning, got: %v", entry.Level)
+			}
+
+			if logged, _ := entry.Data[logrus.ErrorKey].(error); logged == nil || !strings.Contains(logged.Error(), tc.wantErr) {
+				subT.Errorf("expected a logged error containing %q, got: %v", tc.wantErr, logged)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package accesscontrol
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/internal/seetie"
+)
+
+// AnyMethod is the method key of scopes which are required for all methods without own entry.
+const AnyMethod = "*"
+
+var _ AccessControl = &RequiredScope{}
+
+// ScopeError describes the required scopes which have not been granted by a token.
+type ScopeError struct {
+	Missing []string
+}
+
+func (e *ScopeError) Error() string {
+	return "insufficient scope: missing " + strings.Join(e.Missing, " ")
+}
+
+// RequiredScope compares the scopes of the validated tokens with the scopes
+// required for the request method.
+type RequiredScope struct {
+	scopes map[string][]string
+}
+
+// NewRequiredScope creates a new <*RequiredScope> object with the required scopes per
+// method. Scopes of the <AnyMethod> key apply to methods without own entry.
+func NewRequiredScope(scopes map[string][]string) *RequiredScope {
+	return &RequiredScope{scopes: scopes}
+}
+
+// Validate implements the <AccessControl> interface.
+func (r *RequiredScope) Validate(req *http.Request) error {
+	required, exist := r.scopes[req.Method]
+	if !exist {
+		required = r.scopes[AnyMethod]
+	}
+
+	granted, _ := req.Context().Value(request.Scopes).([]string)
+
+	var missing []string
+	for _, scope := range required {
+		if !containsString(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &ScopeError{Missing: missing}
+	}
+	return nil
+}
+
+// grantScopes adds the scopes of the given claim value to the granted scopes of the
+// request. Strings are space separated scope lists, see RFC 8693 section 4.2.
+func grantScopes(req *http.Request, claim interface{}) {
+	var scopes []string
+	switch v := claim.(type) {
+	case string:
+		scopes = strings.Fields(v)
+	case []interface{}:
+		for _, s := range v {
+			scopes = append(scopes, seetie.ToString(s))
+		}
+	case []string:
+		scopes = v
+	}
+
+	if len(scopes) == 0 {
+		return
+	}
+
+	granted, _ := req.Context().Value(request.Scopes).([]string)
+	result := make([]string, 0, len(granted)+len(scopes))
+	result = append(result, granted...)
+	for _, scope := range scopes {
+		if !containsString(result, scope) {
+			result = append(result, scope)
+		}
+	}
+
+	*req = *req.WithContext(context.WithValue(req.Context(), request.Scopes, result))
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
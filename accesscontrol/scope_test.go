@@ -0,0 +1,75 @@
+package accesscontrol_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	ac "github.com/avenga/couper/accesscontrol"
+)
+
+func TestRequiredScope_Validate(t *testing.T) {
+	key := []byte("mySecretK3y")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"scp":   "read write",
+		"roles": []string{"admin"},
+	}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := ac.NewJWT("HS256", "test_ac", nil, nil, ac.Header, "Authorization", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.SetScopeClaims("scp", "roles")
+
+	requiredScope := ac.NewRequiredScope(map[string][]string{
+		http.MethodGet:    {"read"},
+		http.MethodDelete: {"admin", "delete"},
+		ac.AnyMethod:      {"write"},
+	})
+
+	tests := []struct {
+		name        string
+		method      string
+		token       string
+		wantMissing []string
+	}{
+		{"GET", http.MethodGet, token, nil},
+		{"DELETE", http.MethodDelete, token, []string{"delete"}},
+		{"any method", http.MethodPost, token, nil},
+		{"no token", http.MethodGet, "", []string{"read"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+				if err := j.Validate(req); err != nil {
+					subT.Fatal(err)
+				}
+			}
+
+			err := requiredScope.Validate(req)
+			if tt.wantMissing == nil {
+				if err != nil {
+					subT.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			scopeErr, ok := err.(*ac.ScopeError)
+			if !ok {
+				subT.Fatalf("expected a scope error, got: %v", err)
+			}
+			if !reflect.DeepEqual(scopeErr.Missing, tt.wantMissing) {
+				subT.Errorf("expected missing scopes %v, got: %v", tt.wantMissing, scopeErr.Missing)
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ func NewCommand(cmd string) Cmd {
 	switch strings.ToLower(cmd) {
 	case "run":
 		return NewRun(ContextWithSignal(context.Background()))
+	case "verify":
+		return NewVerify()
 	case "version":
 		return NewVersion()
 	default:
@@ -14,5 +14,6 @@ global options:
 available commands:
 
 	run		starts the server
+	verify		validates the configuration file without starting the server
 `)
 }
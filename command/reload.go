@@ -0,0 +1,130 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/config/configload"
+	"github.com/avenga/couper/config/runtime"
+	"github.com/avenga/couper/server"
+)
+
+// WatchInterval defines the interval for configuration file change lookups.
+var WatchInterval = time.Second * 2
+
+// reloader loads the configuration file on SIGHUP or, if enabled,
+// on file changes and applies the result to the listening servers.
+type reloader struct {
+	args             Args
+	config           *config.Couper
+	lastSrc          []byte
+	log              *logrus.Entry
+	serverList       []*server.HTTPServer
+	stopHealthChecks context.CancelFunc
+}
+
+func newReloader(args Args, conf *config.Couper, serverList []*server.HTTPServer, logEntry *logrus.Entry, stopHealthChecks context.CancelFunc) *reloader {
+	return &reloader{
+		args:             args,
+		config:           conf,
+		lastSrc:          conf.Bytes,
+		log:              logEntry,
+		serverList:       serverList,
+		stopHealthChecks: stopHealthChecks,
+	}
+}
+
+func (r *reloader) watch(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	var ticker <-chan time.Time
+	if r.config.Settings.Watch && r.config.Filename != "" {
+		t := time.NewTicker(WatchInterval)
+		defer t.Stop()
+		ticker = t.C
+		r.log.Infof("watching configuration file: %s", r.config.Filename)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			r.reload(ctx)
+		case <-ticker:
+			if r.hasChanged() {
+				r.reload(ctx)
+			}
+		}
+	}
+}
+
+// hasChanged compares the configuration file content with the last seen one.
+// Invalid contents are remembered too, to prevent repetitive reload errors while editing.
+func (r *reloader) hasChanged() bool {
+	src, err := ioutil.ReadFile(r.config.Filename)
+	if err != nil {
+		r.log.WithError(err).Error("configuration reload failed")
+		return false
+	}
+	changed := !bytes.Equal(src, r.lastSrc)
+	r.lastSrc = src
+	return changed
+}
+
+func (r *reloader) reload(ctx context.Context) {
+	conf, err := r.load()
+	if err != nil {
+		r.log.WithError(err).Error("configuration reload failed, keeping the current configuration")
+		return
+	}
+
+	r.stopHealthChecks()
+	r.stopHealthChecks = startHealthChecks(ctx, conf)
+	r.config = conf
+	r.log.Info("configuration reloaded")
+}
+
+// load creates a new server configuration from the configuration file and applies it.
+func (r *reloader) load() (*config.Couper, error) {
+	if r.config.Filename == "" {
+		return nil, fmt.Errorf("missing configuration filename")
+	}
+
+	conf, err := configload.LoadFile(r.config.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// the log format is set up once on startup
+	conf.Settings.LogFormat = r.config.Settings.LogFormat
+	if err = applySettings(r.args, conf); err != nil {
+		return nil, err
+	}
+
+	if !reflect.DeepEqual(conf.Settings, r.config.Settings) {
+		return nil, fmt.Errorf("changed settings require a restart")
+	}
+
+	srvConf, err := runtime.NewServerConfiguration(conf, r.log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = server.ReloadServerList(r.serverList, conf.Context, srvConf); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
@@ -25,19 +25,10 @@ func NewRun(ctx context.Context) *Run {
 }
 
 func (r Run) Execute(args Args, config *config.Couper, logEntry *logrus.Entry) error {
-	// TODO: Extract and execute flagSet & env handling in a more generic way for future commands.
-	set := flag.NewFlagSet("settings", flag.ContinueOnError)
-	set.StringVar(&config.Settings.HealthPath, "health-path", config.Settings.HealthPath, "-health-path /healthz")
-	set.IntVar(&config.Settings.DefaultPort, "p", config.Settings.DefaultPort, "-p 8080")
-	set.BoolVar(&config.Settings.XForwardedHost, "xfh", config.Settings.XForwardedHost, "-xfh")
-	set.BoolVar(&config.Settings.NoProxyFromEnv, "no-proxy-from-env", config.Settings.NoProxyFromEnv, "-no-proxy-from-env")
-	set.StringVar(&config.Settings.RequestIDFormat, "request-id-format", config.Settings.RequestIDFormat, "-request-id-format uuid4")
-	if err := set.Parse(args.Filter(set)); err != nil {
+	if err := applySettings(args, config); err != nil {
 		return err
 	}
 
-	env.Decode(config.Settings)
-
 	timings := runtime.DefaultTimings
 	env.Decode(&timings)
 
@@ -51,10 +42,44 @@ func (r Run) Execute(args Args, config *config.Couper, logEntry *logrus.Entry) e
 	for _, srv := range serverList {
 		srv.Listen()
 	}
+
+	stopHealthChecks := startHealthChecks(r.context, config)
+
+	reloader := newReloader(args, config, serverList, logEntry, stopHealthChecks)
+	go reloader.watch(r.context)
+
 	listenCmdShutdown()
 	return nil
 }
 
+// applySettings overrides the configured settings with the given flag args and environment variables.
+func applySettings(args Args, config *config.Couper) error {
+	// TODO: Extract and execute flagSet & env handling in a more generic way for future commands.
+	set := flag.NewFlagSet("settings", flag.ContinueOnError)
+	set.StringVar(&config.Settings.HealthPath, "health-path", config.Settings.HealthPath, "-health-path /healthz")
+	set.IntVar(&config.Settings.DefaultPort, "p", config.Settings.DefaultPort, "-p 8080")
+	set.BoolVar(&config.Settings.XForwardedHost, "xfh", config.Settings.XForwardedHost, "-xfh")
+	set.BoolVar(&config.Settings.NoProxyFromEnv, "no-proxy-from-env", config.Settings.NoProxyFromEnv, "-no-proxy-from-env")
+	set.StringVar(&config.Settings.RequestIDFormat, "request-id-format", config.Settings.RequestIDFormat, "-request-id-format uuid4")
+	set.BoolVar(&config.Settings.Watch, "watch", config.Settings.Watch, "-watch")
+	if err := set.Parse(args.Filter(set)); err != nil {
+		return err
+	}
+
+	env.Decode(config.Settings)
+	return nil
+}
+
+// startHealthChecks starts the backend health checks of the given configuration.
+// The returned function stops them.
+func startHealthChecks(ctx context.Context, conf *config.Couper) context.CancelFunc {
+	healthCtx, cancel := context.WithCancel(ctx)
+	for _, backend := range conf.Context.Backends() {
+		backend.Start(healthCtx)
+	}
+	return cancel
+}
+
 func (r Run) Usage() string {
 	panic("implement me")
 }
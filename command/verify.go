@@ -0,0 +1,35 @@
+package command
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/config/runtime"
+)
+
+var _ Cmd = &Verify{}
+
+// Verify validates the given configuration the same way
+// the run command does, without listening on any port.
+type Verify struct{}
+
+func NewVerify() *Verify {
+	return &Verify{}
+}
+
+func (v Verify) Execute(args Args, config *config.Couper, logEntry *logrus.Entry) error {
+	if err := applySettings(args, config); err != nil {
+		return err
+	}
+
+	if _, err := runtime.NewServerConfiguration(config, logEntry); err != nil {
+		return err
+	}
+
+	logEntry.Infof("configuration is valid: %s", config.Filename)
+	return nil
+}
+
+func (v Verify) Usage() string {
+	return "couper verify -f couper.hcl"
+}
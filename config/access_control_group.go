@@ -0,0 +1,8 @@
+package config
+
+// AccessControlGroup represents the <AccessControlGroup> object.
+type AccessControlGroup struct {
+	All  []string `hcl:"all,optional"`
+	Any  []string `hcl:"any,optional"`
+	Name string   `hcl:"name,label"`
+}
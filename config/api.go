@@ -1,13 +1,17 @@
 package config
 
+import "github.com/hashicorp/hcl/v2"
+
 // API represents the <API> object.
 type API struct {
-	AccessControl        []string  `hcl:"access_control,optional"`
-	CORS                 *CORS     `hcl:"cors,block"`
-	BasePath             string    `hcl:"base_path,optional"`
-	DisableAccessControl []string  `hcl:"disable_access_control,optional"`
-	Endpoints            Endpoints `hcl:"endpoint,block"`
-	ErrorFile            string    `hcl:"error_file,optional"`
+	AccessControl        []string       `hcl:"access_control,optional"`
+	CORS                 *CORS          `hcl:"cors,block"`
+	BasePath             string         `hcl:"base_path,optional"`
+	DisableAccessControl []string       `hcl:"disable_access_control,optional"`
+	Endpoints            Endpoints      `hcl:"endpoint,block"`
+	ErrorFile            string         `hcl:"error_file,optional"`
+	RateLimit            []string       `hcl:"rate_limit,optional"`
+	RequiredScopes       hcl.Expression `hcl:"required_scopes,optional"`
 }
 
 // APIs represents a list of <API> objects.
@@ -0,0 +1,19 @@
+package config
+
+// APIKey represents the <APIKey> object.
+type APIKey struct {
+	Cookie     string         `hcl:"cookie,optional"`
+	ErrorFile  string         `hcl:"error_file,optional"`
+	Header     string         `hcl:"header,optional"`
+	Keys       []*APIKeyEntry `hcl:"key,block"`
+	KeysFile   string         `hcl:"keys_file,optional"`
+	Name       string         `hcl:"name,label"`
+	QueryParam string         `hcl:"query_param,optional"`
+}
+
+// APIKeyEntry represents a <key> block of an <APIKey> object.
+type APIKeyEntry struct {
+	ID       string            `hcl:"id,label"`
+	Metadata map[string]string `hcl:"metadata,optional"`
+	Value    string            `hcl:"value"`
+}
@@ -12,19 +12,24 @@ var BackendInlineSchema = Backend{}.Schema(true)
 
 // Backend represents the <Backend> object.
 type Backend struct {
-	BasicAuth              string   `hcl:"basic_auth,optional"`
-	ConnectTimeout         string   `hcl:"connect_timeout,optional"`
-	DisableCertValidation  bool     `hcl:"disable_certificate_validation,optional"`
-	DisableConnectionReuse bool     `hcl:"disable_connection_reuse,optional"`
-	HTTP2                  bool     `hcl:"http2,optional"`
-	MaxConnections         int      `hcl:"max_connections,optional"`
-	Name                   string   `hcl:"name,label"`
-	OpenAPI                *OpenAPI `hcl:"openapi,block"`
-	PathPrefix             string   `hcl:"path_prefix,optional"`
-	Proxy                  string   `hcl:"proxy,optional"`
-	Remain                 hcl.Body `hcl:",remain"`
-	TTFBTimeout            string   `hcl:"ttfb_timeout,optional"`
-	Timeout                string   `hcl:"timeout,optional"`
+	BasicAuth              string          `hcl:"basic_auth,optional"`
+	CircuitBreaker         *CircuitBreaker `hcl:"circuit_breaker,block"`
+	ConnectTimeout         string          `hcl:"connect_timeout,optional"`
+	DisableCertValidation  bool            `hcl:"disable_certificate_validation,optional"`
+	DisableConnectionReuse bool            `hcl:"disable_connection_reuse,optional"`
+	HTTP2                  bool            `hcl:"http2,optional"`
+	Health                 *Health         `hcl:"health,block"`
+	LoadBalancer           *LoadBalancer   `hcl:"load_balancer,block"`
+	MaxConnections         int             `hcl:"max_connections,optional"`
+	Name                   string          `hcl:"name,label"`
+	OAuth2                 *OAuth2         `hcl:"oauth2,block"`
+	OpenAPI                *OpenAPI        `hcl:"openapi,block"`
+	Proxy                  string          `hcl:"proxy,optional"`
+	Remain                 hcl.Body        `hcl:",remain"`
+	Retry                  *Retry          `hcl:"retry,block"`
+	Throttle               *Throttle       `hcl:"throttle,block"`
+	TTFBTimeout            string          `hcl:"ttfb_timeout,optional"`
+	Timeout                string          `hcl:"timeout,optional"`
 }
 
 // HCLBody implements the <Inline> interface.
@@ -46,8 +51,9 @@ func (b Backend) Schema(inline bool) *hcl.BodySchema {
 
 	type Inline struct {
 		meta.Attributes
-		Hostname string `hcl:"hostname,optional"`
-		Origin   string `hcl:"origin,optional"`
+		Hostname   string `hcl:"hostname,optional"`
+		Origin     string `hcl:"origin,optional"`
+		PathPrefix string `hcl:"path_prefix,optional"`
 	}
 
 	schema, _ = gohcl.ImpliedBodySchema(&Inline{})
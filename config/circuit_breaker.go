@@ -0,0 +1,11 @@
+package config
+
+// CircuitBreaker represents the <CircuitBreaker> object.
+type CircuitBreaker struct {
+	ConsecutiveFailures int     `hcl:"consecutive_failures,optional"`
+	ErrorRate           float64 `hcl:"error_rate,optional"`
+	HalfOpenRequests    int     `hcl:"half_open_requests,optional"`
+	MinRequests         int     `hcl:"min_requests,optional"`
+	OpenTimeout         string  `hcl:"open_timeout,optional"`
+	Window              string  `hcl:"window,optional"`
+}
@@ -0,0 +1,12 @@
+package config
+
+// ClientCertificate represents the "client_certificate" config block
+type ClientCertificate struct {
+	AllowedCommonNames    []string `hcl:"allowed_common_names,optional"`
+	AllowedDNSNames       []string `hcl:"allowed_dns_names,optional"`
+	AllowedEmailAddresses []string `hcl:"allowed_email_addresses,optional"`
+	CAFile                string   `hcl:"ca_file"`
+	CRLFile               string   `hcl:"crl_file,optional"`
+	ErrorFile             string   `hcl:"error_file,optional"`
+	Name                  string   `hcl:"name,label"`
+}
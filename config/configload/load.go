@@ -21,13 +21,14 @@ import (
 )
 
 const (
-	backend     = "backend"
-	definitions = "definitions"
-	nameLabel   = "name"
-	proxy       = "proxy"
-	request     = "request"
-	server      = "server"
-	settings    = "settings"
+	backend      = "backend"
+	definitions  = "definitions"
+	loadBalancer = "load_balancer"
+	nameLabel    = "name"
+	proxy        = "proxy"
+	request      = "request"
+	server       = "server"
+	settings     = "settings"
 	// defaultNameLabel maps the the hcl label attr 'name'.
 	defaultNameLabel = "default"
 )
@@ -35,19 +36,31 @@ const (
 var regexProxyRequestLabel = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 
 func LoadFile(filePath string) (*config.Couper, error) {
-	_, err := startup.SetWorkingDirectory(filePath)
+	// obtain the absolute path before changing the working directory
+	absPath, err := filepath.Abs(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	filename := filepath.Base(filePath)
+	_, err = startup.SetWorkingDirectory(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	filename := filepath.Base(absPath)
 
 	src, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	return LoadBytes(src, filename)
+	couperConfig, err := LoadBytes(src, filename)
+	if err != nil {
+		return nil, err
+	}
+	couperConfig.Filename = absPath
+
+	return couperConfig, nil
 }
 
 func LoadBytes(src []byte, filename string) (*config.Couper, error) {
@@ -117,6 +130,18 @@ func LoadConfig(body hcl.Body, src []byte) (*config.Couper, error) {
 		}
 	}
 
+	// Keep the backend bodies as possible references of nested blocks like oauth2.
+	for _, be := range definedBackends {
+		body, err := definedBackends.WithName(be.name)
+		if err != nil {
+			return nil, err
+		}
+		couperConfig.Definitions.Backend = append(couperConfig.Definitions.Backend, &config.Backend{
+			Name:   be.name,
+			Remain: body,
+		})
+	}
+
 	// Read per server block and merge backend settings which results in a final server configuration.
 	for _, serverBlock := range content.Blocks.OfType(server) {
 		serverConfig := &config.Server{}
@@ -512,13 +537,16 @@ func newBackendFromURL(rawURL string) (hcl.Body, error) {
 	}), nil
 }
 
-// validateOrigin checks at least for an origin attribute definition.
+// validateOrigin checks at least for an origin attribute or load_balancer block definition.
 func validateOrigin(merged hcl.Body) error {
 	if merged == nil {
 		return fmt.Errorf("missing backend reference or definition")
 	}
 
-	content, _, diags := merged.PartialContent(&hcl.BodySchema{Attributes: []hcl.AttributeSchema{{Name: "origin"}}})
+	content, _, diags := merged.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "origin"}},
+		Blocks:     []hcl.BlockHeaderSchema{{Type: loadBalancer}},
+	})
 	if diags.HasErrors() {
 		return diags
 	}
@@ -529,7 +557,7 @@ func validateOrigin(merged hcl.Body) error {
 	}
 
 	_, ok := content.Attributes["origin"]
-	if !ok {
+	if !ok && len(content.Blocks.OfType(loadBalancer)) == 0 {
 		bodyRange := merged.MissingItemRange()
 		if bodyRange.Filename == "<empty>" {
 			return err
@@ -12,6 +12,7 @@ type Couper struct {
 	Bytes       []byte
 	Context     *eval.Context
 	Definitions *Definitions `hcl:"definitions,block"`
-	Servers     Servers      `hcl:"server,block"`
-	Settings    *Settings    `hcl:"settings,block"`
+	Filename    string
+	Servers     Servers   `hcl:"server,block"`
+	Settings    *Settings `hcl:"settings,block"`
 }
@@ -2,6 +2,15 @@ package config
 
 // Definitions represents the <Definitions> object.
 type Definitions struct {
-	BasicAuth []*BasicAuth `hcl:"basic_auth,block"`
-	JWT       []*JWT       `hcl:"jwt,block"`
+	AccessControlGroup []*AccessControlGroup `hcl:"access_control_group,block"`
+	APIKey             []*APIKey             `hcl:"api_key,block"`
+	Backend            []*Backend            `hcl:"backend,block"`
+	BasicAuth          []*BasicAuth          `hcl:"basic_auth,block"`
+	ClientCertificate  []*ClientCertificate  `hcl:"client_certificate,block"`
+	Introspection      []*Introspection      `hcl:"introspection,block"`
+	IPFilter           []*IPFilter           `hcl:"ip_filter,block"`
+	JWT                []*JWT                `hcl:"jwt,block"`
+	JWTSigningProfile  []*JWTSigningProfile  `hcl:"jwt_signing_profile,block"`
+	OIDC               []*OIDC               `hcl:"oidc,block"`
+	RateLimit          []*RateLimit          `hcl:"rate_limit,block"`
 }
@@ -11,12 +11,14 @@ var _ Inline = &Endpoint{}
 
 // Endpoint represents the <Endpoint> object.
 type Endpoint struct {
-	AccessControl        []string  `hcl:"access_control,optional"`
-	DisableAccessControl []string  `hcl:"disable_access_control,optional"`
-	Pattern              string    `hcl:"pattern,label"`
-	Remain               hcl.Body  `hcl:",remain"`
-	RequestBodyLimit     string    `hcl:"request_body_limit,optional"`
-	Response             *Response `hcl:"response,block"`
+	AccessControl        []string       `hcl:"access_control,optional"`
+	DisableAccessControl []string       `hcl:"disable_access_control,optional"`
+	Pattern              string         `hcl:"pattern,label"`
+	RateLimit            []string       `hcl:"rate_limit,optional"`
+	Remain               hcl.Body       `hcl:",remain"`
+	RequestBodyLimit     string         `hcl:"request_body_limit,optional"`
+	RequiredScopes       hcl.Expression `hcl:"required_scopes,optional"`
+	Response             *Response      `hcl:"response,block"`
 	// internally used
 	Proxies  Proxies
 	Requests Requests
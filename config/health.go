@@ -0,0 +1,11 @@
+package config
+
+// Health represents the <Health> object.
+type Health struct {
+	ExpectedStatus   []int  `hcl:"expected_status,optional"`
+	FailureThreshold int    `hcl:"failure_threshold,optional"`
+	Interval         string `hcl:"interval,optional"`
+	Path             string `hcl:"path,optional"`
+	SuccessThreshold int    `hcl:"success_threshold,optional"`
+	Timeout          string `hcl:"timeout,optional"`
+}
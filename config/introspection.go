@@ -0,0 +1,15 @@
+package config
+
+// Introspection represents the <Introspection> object.
+type Introspection struct {
+	AllowedClientIDs []string `hcl:"allowed_client_ids,optional"`
+	BackendName      string   `hcl:"backend,optional"`
+	ClientID         string   `hcl:"client_id,optional"`
+	ClientSecret     string   `hcl:"client_secret,optional"`
+	Endpoint         string   `hcl:"endpoint"`
+	ErrorFile        string   `hcl:"error_file,optional"`
+	Header           string   `hcl:"header,optional"`
+	Name             string   `hcl:"name,label"`
+	Realm            string   `hcl:"realm,optional"`
+	TTL              string   `hcl:"ttl,optional"`
+}
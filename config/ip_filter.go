@@ -0,0 +1,11 @@
+package config
+
+// IPFilter represents the <IPFilter> object.
+type IPFilter struct {
+	Allow     []string `hcl:"allow,optional"`
+	AllowFile string   `hcl:"allow_file,optional"`
+	Deny      []string `hcl:"deny,optional"`
+	DenyFile  string   `hcl:"deny_file,optional"`
+	ErrorFile string   `hcl:"error_file,optional"`
+	Name      string   `hcl:"name,label"`
+}
@@ -7,14 +7,23 @@ import (
 type Claims hcl.Expression
 
 type JWT struct {
+	BackendName        string   `hcl:"backend,optional"`
 	Claims             Claims   `hcl:"claims,optional"`
 	ClaimsRequired     []string `hcl:"required_claims,optional"`
 	Cookie             string   `hcl:"cookie,optional"`
+	ErrorFile          string   `hcl:"error_file,optional"`
 	Header             string   `hcl:"header,optional"`
+	JWKSFile           string   `hcl:"jwks_file,optional"`
+	JWKSTTL            string   `hcl:"jwks_ttl,optional"`
+	JWKSURL            string   `hcl:"jwks_url,optional"`
 	Key                string   `hcl:"key,optional"`
 	KeyFile            string   `hcl:"key_file,optional"`
 	Name               string   `hcl:"name,label"`
 	PostParam          string   `hcl:"post_param,optional"`
 	QueryParam         string   `hcl:"query_param,optional"`
-	SignatureAlgorithm string   `hcl:"signature_algorithm"`
+	Realm              string   `hcl:"realm,optional"`
+	RolesClaim         string   `hcl:"roles_claim,optional"`
+	ScopeClaim         string   `hcl:"scope_claim,optional"`
+	SignatureAlgorithm string   `hcl:"signature_algorithm,optional"`
+	StripToken         bool     `hcl:"strip_token,optional"`
 }
@@ -0,0 +1,16 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// LoadBalancer represents the <LoadBalancer> object.
+type LoadBalancer struct {
+	HashKey   hcl.Expression `hcl:"hash_key,optional"`
+	Strategy  string         `hcl:"strategy,optional"`
+	Upstreams []*Upstream    `hcl:"upstream,block"`
+}
+
+// Upstream represents the <Upstream> object.
+type Upstream struct {
+	Origin string `hcl:"origin"`
+	Weight int    `hcl:"weight,optional"`
+}
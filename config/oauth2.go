@@ -0,0 +1,12 @@
+package config
+
+// OAuth2 represents the <OAuth2> object.
+type OAuth2 struct {
+	BackendName             string `hcl:"backend,optional"`
+	ClientID                string `hcl:"client_id"`
+	ClientSecret            string `hcl:"client_secret"`
+	GrantType               string `hcl:"grant_type,optional"`
+	Scope                   string `hcl:"scope,optional"`
+	TokenEndpoint           string `hcl:"token_endpoint"`
+	TokenEndpointAuthMethod string `hcl:"token_endpoint_auth_method,optional"`
+}
@@ -0,0 +1,17 @@
+package config
+
+// OIDC represents the <OIDC> object.
+type OIDC struct {
+	BackendName      string `hcl:"backend,optional"`
+	ClientID         string `hcl:"client_id"`
+	ClientSecret     string `hcl:"client_secret,optional"`
+	ConfigurationTTL string `hcl:"configuration_ttl,optional"`
+	ConfigurationURL string `hcl:"configuration_url"`
+	CookieName       string `hcl:"cookie_name,optional"`
+	ErrorFile        string `hcl:"error_file,optional"`
+	Name             string `hcl:"name,label"`
+	RedirectURI      string `hcl:"redirect_uri"`
+	Scope            string `hcl:"scope,optional"`
+	SessionSecret    string `hcl:"session_secret"`
+	SessionTTL       string `hcl:"session_ttl,optional"`
+}
@@ -15,6 +15,7 @@ type Proxy struct {
 	Name        string   `hcl:"name,label"`
 	URL         string   `hcl:"url,optional"`
 	Remain      hcl.Body `hcl:",remain"`
+	Retry       *Retry   `hcl:"retry,block"`
 	// internally used
 	Backend hcl.Body
 }
@@ -0,0 +1,13 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// RateLimit represents the <RateLimit> object.
+type RateLimit struct {
+	Algorithm string         `hcl:"algorithm,optional"`
+	Key       hcl.Expression `hcl:"key,optional"`
+	Limit     int            `hcl:"limit"`
+	MaxKeys   int            `hcl:"max_keys,optional"`
+	Name      string         `hcl:"name,label"`
+	Window    string         `hcl:"window"`
+}
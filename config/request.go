@@ -15,6 +15,7 @@ type Request struct {
 	Method      string   `hcl:"method,optional"`
 	Name        string   `hcl:"name,label"`
 	Remain      hcl.Body `hcl:",remain"`
+	Retry       *Retry   `hcl:"retry,block"`
 	URL         string   `hcl:"url,optional"`
 	// Internally used
 	Backend hcl.Body
@@ -5,13 +5,19 @@ type ContextKey uint8
 const (
 	UID ContextKey = iota
 	AccessControls
+	AccessControlError
+	AccessControlsGranted
 	BackendName
 	Endpoint
 	EndpointKind
 	OpenAPI
 	PathParams
+	RateLimitFallback
+	RoundTripAttempt
 	RoundTripName
 	RoundTripProxy
+	Scopes
 	ServerName
+	UpstreamTimings
 	Wildcard
 )
@@ -0,0 +1,11 @@
+package config
+
+// Retry represents the <Retry> object.
+type Retry struct {
+	Attempts      int      `hcl:"attempts,optional"`
+	Backoff       string   `hcl:"backoff,optional"`
+	MaxBackoff    string   `hcl:"max_backoff,optional"`
+	Methods       []string `hcl:"methods,optional"`
+	NetworkErrors *bool    `hcl:"network_errors,optional"`
+	StatusCodes   []int    `hcl:"status_codes,optional"`
+}
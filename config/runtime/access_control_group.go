@@ -0,0 +1,76 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config"
+)
+
+// configureAccessControlGroups adds the given groups to the access controls. Groups
+// may reference other groups in any order, circular references are not allowed.
+func configureAccessControlGroups(groups []*config.AccessControlGroup, accessControls ac.Map) error {
+	groupConfs := make(map[string]*config.AccessControlGroup)
+	for _, groupConf := range groups {
+		name, err := validateACName(accessControls, groupConf.Name, "access_control_group")
+		if err != nil {
+			return err
+		}
+		if _, exist := groupConfs[name]; exist {
+			return fmt.Errorf("access control: '%s' already exists", name)
+		}
+		groupConfs[name] = groupConf
+	}
+
+	resolving := make(map[string]bool)
+	var resolve func(name string) (ac.AccessControl, error)
+	resolve = func(name string) (ac.AccessControl, error) {
+		if control, exist := accessControls[name]; exist {
+			return control, nil
+		}
+
+		groupConf, exist := groupConfs[name]
+		if !exist {
+			return nil, fmt.Errorf("access control is not defined: %s", name)
+		}
+
+		if resolving[name] {
+			return nil, fmt.Errorf("access_control_group %q: circular reference", name)
+		}
+		resolving[name] = true
+
+		if (len(groupConf.All) > 0) == (len(groupConf.Any) > 0) {
+			return nil, fmt.Errorf("access_control_group %q: either all or any must be specified", name)
+		}
+
+		mode, members := ac.AllOf, groupConf.All
+		if len(groupConf.Any) > 0 {
+			mode, members = ac.AnyOf, groupConf.Any
+		}
+
+		var controls ac.List
+		for _, member := range members {
+			control, err := resolve(strings.TrimSpace(member))
+			if err != nil {
+				return nil, err
+			}
+			controls = append(controls, control)
+		}
+
+		group, err := ac.NewGroup(mode, controls...)
+		if err != nil {
+			return nil, fmt.Errorf("loading access_control_group %q definition failed: %s", name, err)
+		}
+
+		accessControls[name] = group
+		return group, nil
+	}
+
+	for _, groupConf := range groups {
+		if _, err := resolve(strings.TrimSpace(groupConf.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
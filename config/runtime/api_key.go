@@ -0,0 +1,65 @@
+package runtime
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config"
+)
+
+// newAPIKey creates the api key access control of the given configuration. Inline
+// key values are hashed unless they are given as sha256 or bcrypt hash already.
+func newAPIKey(apiKeyConf *config.APIKey, log *logrus.Entry) (*ac.APIKey, error) {
+	source, sourceKey := ac.Unknown, ""
+	for _, src := range []struct {
+		key    string
+		source ac.Source
+	}{
+		{apiKeyConf.Cookie, ac.Cookie},
+		{apiKeyConf.Header, ac.Header},
+		{apiKeyConf.QueryParam, ac.QueryParam},
+	} {
+		if src.key == "" {
+			continue
+		}
+		if source != ac.Unknown {
+			return nil, fmt.Errorf("only one of cookie, header or query_param is allowed")
+		}
+		source, sourceKey = src.source, src.key
+	}
+
+	if source == ac.Unknown {
+		return nil, fmt.Errorf("one of cookie, header or query_param is required")
+	}
+
+	opts := &ac.APIKeyOptions{
+		Log:       log,
+		Name:      apiKeyConf.Name,
+		Source:    source,
+		SourceKey: sourceKey,
+	}
+
+	if apiKeyConf.KeysFile != "" {
+		file, err := filepath.Abs(apiKeyConf.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		opts.File = file
+	}
+
+	for _, key := range apiKeyConf.Keys {
+		if key.Value == "" {
+			return nil, fmt.Errorf("key %q: empty value", key.ID)
+		}
+		opts.Keys = append(opts.Keys, &ac.APIKeyEntry{
+			Hash:     ac.HashAPIKey(key.Value),
+			ID:       key.ID,
+			Metadata: key.Metadata,
+		})
+	}
+
+	return ac.NewAPIKey(opts)
+}
@@ -0,0 +1,58 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/avenga/couper/config"
+)
+
+// backendRegistry holds the stateful components of the definitions backends, e.g. the
+// load balancer, so that all use sites of a backend name share the same state.
+type backendRegistry struct {
+	definitions map[string]bool
+	components  map[string]*backendComponent
+}
+
+type backendComponent struct {
+	key   string
+	value interface{}
+}
+
+func newBackendRegistry(definitions *config.Definitions) *backendRegistry {
+	registry := &backendRegistry{
+		definitions: make(map[string]bool),
+		components:  make(map[string]*backendComponent),
+	}
+
+	if definitions != nil {
+		for _, beConf := range definitions.Backend {
+			registry.definitions[beConf.Name] = true
+		}
+	}
+	return registry
+}
+
+// get returns the component of the given kind for the named backend and creates it on the
+// first call. The key identifies the component configuration, refinements of a backend must
+// not change it. Backends which are not defined in the definitions block are never shared.
+func (r *backendRegistry) get(name, kind, key string, create func() (interface{}, error)) (interface{}, error) {
+	if r == nil || !r.definitions[name] {
+		return create()
+	}
+
+	id := name + "." + kind
+	if component, exist := r.components[id]; exist {
+		if component.key != key {
+			return nil, fmt.Errorf("backend %q: %s: refinements must not change the configuration", name, kind)
+		}
+		return component.value, nil
+	}
+
+	value, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	r.components[id] = &backendComponent{key: key, value: value}
+	return value, nil
+}
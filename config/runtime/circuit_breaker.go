@@ -0,0 +1,84 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/handler/transport"
+)
+
+var DefaultCircuitBreaker = &config.CircuitBreaker{
+	ConsecutiveFailures: 5,
+	HalfOpenRequests:    1,
+	MinRequests:         10,
+	OpenTimeout:         "30s",
+	Window:              "10s",
+}
+
+// newCircuitBreaker returns the circuit breaker of the given backend configuration, if any.
+// All use sites of a definitions backend share one circuit breaker and so its failure counts.
+func newCircuitBreaker(beConf config.Backend, backends *backendRegistry, log *logrus.Entry) (*transport.CircuitBreaker, error) {
+	opts, err := newCircuitBreakerOptions(beConf.CircuitBreaker)
+	if err != nil {
+		return nil, fmt.Errorf("backend %q: circuit_breaker: %v", beConf.Name, err)
+	}
+
+	if opts == nil {
+		return nil, nil
+	}
+
+	circuitBreaker, err := backends.get(beConf.Name, "circuit_breaker", fmt.Sprintf("%+v", *opts), func() (interface{}, error) {
+		return transport.NewCircuitBreaker(opts, log.WithField("backend", beConf.Name)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return circuitBreaker.(*transport.CircuitBreaker), nil
+}
+
+func newCircuitBreakerOptions(cb *config.CircuitBreaker) (*transport.CircuitBreakerOptions, error) {
+	if cb == nil {
+		return nil, nil
+	}
+
+	if cb.ErrorRate < 0 || cb.ErrorRate > 1 {
+		return nil, fmt.Errorf("error_rate must be between 0 and 1: %v", cb.ErrorRate)
+	}
+
+	opts := &transport.CircuitBreakerOptions{
+		ConsecutiveFailures: cb.ConsecutiveFailures,
+		ErrorRate:           cb.ErrorRate,
+		HalfOpenRequests:    cb.HalfOpenRequests,
+		MinRequests:         cb.MinRequests,
+	}
+
+	// the consecutive failures threshold applies by default if no error rate is configured
+	if opts.ConsecutiveFailures < 1 && opts.ErrorRate == 0 {
+		opts.ConsecutiveFailures = DefaultCircuitBreaker.ConsecutiveFailures
+	}
+	if opts.HalfOpenRequests < 1 {
+		opts.HalfOpenRequests = DefaultCircuitBreaker.HalfOpenRequests
+	}
+	if opts.MinRequests < 1 {
+		opts.MinRequests = DefaultCircuitBreaker.MinRequests
+	}
+
+	openTimeout, window := cb.OpenTimeout, cb.Window
+	if openTimeout == "" {
+		openTimeout = DefaultCircuitBreaker.OpenTimeout
+	}
+	if window == "" {
+		window = DefaultCircuitBreaker.Window
+	}
+
+	if err := parseDuration(openTimeout, &opts.OpenTimeout); err != nil {
+		return nil, err
+	}
+	if err := parseDuration(window, &opts.Window); err != nil {
+		return nil, err
+	}
+
+	return opts, nil
+}
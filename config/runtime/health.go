@@ -0,0 +1,117 @@
+package runtime
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/handler/transport"
+	"github.com/avenga/couper/internal/seetie"
+)
+
+var DefaultHealth = &config.Health{
+	ExpectedStatus:   []int{http.StatusOK},
+	FailureThreshold: 2,
+	Interval:         "5s",
+	Path:             "/",
+	SuccessThreshold: 1,
+	Timeout:          "2s",
+}
+
+// configureHealthCheck registers a health check for the origins of the given backend configuration.
+// Backends without a load_balancer are checked with their static origin. The returned balancer
+// selects the healthy origins and is shared by all backends with the same health checked origins.
+func configureHealthCheck(evalCtx *hcl.EvalContext, beConf config.Backend, balancer *transport.Balancer,
+	tc *transport.Config, confCtx *eval.Context, log *logrus.Entry) (*transport.Balancer, error) {
+	opts, err := newHealthCheckOptions(beConf.Health)
+	if err != nil {
+		return nil, fmt.Errorf("backend %q: health: %v", beConf.Name, err)
+	}
+
+	content, _, diags := beConf.Remain.PartialContent(config.BackendInlineSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if balancer == nil {
+		origin, oerr := transport.NewOrigin(getAttribute(evalCtx, "origin", content), 1)
+		if oerr != nil {
+			return nil, fmt.Errorf("backend %q: health: origin must not depend on request variables: %v", beConf.Name, oerr)
+		}
+
+		if balancer, err = transport.NewBalancer("", nil, []*transport.Origin{origin}); err != nil {
+			return nil, err
+		}
+	}
+
+	healthCheck := transport.NewHealthCheck(opts, balancer, tc, getAttribute(evalCtx, "hostname", content), log)
+
+	if registered, exist := confCtx.Backends()[beConf.Name]; exist {
+		if hc, ok := registered.(*transport.HealthCheck); ok && hc.Key() == healthCheck.Key() {
+			return hc.Balancer(), nil
+		}
+		return nil, fmt.Errorf("backend %q: health: refinements must not change the origins or health configuration", beConf.Name)
+	}
+
+	confCtx.AddBackend(beConf.Name, healthCheck)
+	return balancer, nil
+}
+
+func newHealthCheckOptions(health *config.Health) (*transport.HealthCheckOptions, error) {
+	opts := &transport.HealthCheckOptions{
+		ExpectedStatus:   health.ExpectedStatus,
+		FailureThreshold: health.FailureThreshold,
+		Path:             health.Path,
+		SuccessThreshold: health.SuccessThreshold,
+	}
+
+	if len(opts.ExpectedStatus) == 0 {
+		opts.ExpectedStatus = DefaultHealth.ExpectedStatus
+	}
+	if opts.FailureThreshold < 1 {
+		opts.FailureThreshold = DefaultHealth.FailureThreshold
+	}
+	if opts.Path == "" {
+		opts.Path = DefaultHealth.Path
+	} else if !strings.HasPrefix(opts.Path, "/") {
+		opts.Path = "/" + opts.Path
+	}
+	if opts.SuccessThreshold < 1 {
+		opts.SuccessThreshold = DefaultHealth.SuccessThreshold
+	}
+
+	interval, timeout := health.Interval, health.Timeout
+	if interval == "" {
+		interval = DefaultHealth.Interval
+	}
+	if timeout == "" {
+		timeout = DefaultHealth.Timeout
+	}
+
+	if err := parseDuration(interval, &opts.Interval); err != nil {
+		return nil, err
+	}
+	if err := parseDuration(timeout, &opts.Timeout); err != nil {
+		return nil, err
+	}
+
+	if opts.Interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive: %q", interval)
+	}
+
+	return opts, nil
+}
+
+func getAttribute(ctx *hcl.EvalContext, name string, content *hcl.BodyContent) string {
+	attr, ok := content.Attributes[name]
+	if !ok {
+		return ""
+	}
+	v, _ := attr.Expr.Value(ctx)
+	return seetie.ValueToString(v)
+}
@@ -0,0 +1,46 @@
+package runtime
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config"
+)
+
+// newIntrospection creates the token introspection access control of the given configuration. The
+// introspection requests are sent via the referenced backend or a backend with the origin of the endpoint.
+func newIntrospection(evalCtx *hcl.EvalContext, introspectionConf *config.Introspection, log *logrus.Entry, conf *config.Couper, backends *backendRegistry) (*ac.Introspection, error) {
+	u, err := url.Parse(introspectionConf.Endpoint)
+	if introspectionConf.BackendName == "" && (err != nil || u.Host == "") {
+		return nil, fmt.Errorf("invalid endpoint: %q", introspectionConf.Endpoint)
+	}
+
+	opts := &ac.IntrospectionOptions{
+		AllowedClientIDs: introspectionConf.AllowedClientIDs,
+		ClientID:         introspectionConf.ClientID,
+		ClientSecret:     introspectionConf.ClientSecret,
+		Endpoint:         introspectionConf.Endpoint,
+		Header:           introspectionConf.Header,
+		Name:             introspectionConf.Name,
+		Realm:            introspectionConf.Realm,
+	}
+
+	if err = parseDuration(introspectionConf.TTL, &opts.TTL); err != nil {
+		return nil, fmt.Errorf("ttl: %v", err)
+	}
+
+	backendCtx, err := newBackendBody(conf, introspectionConf.BackendName, u, introspectionConf.Name+"_introspection")
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Backend, err = newBackend(evalCtx, backendCtx, log, conf, backends); err != nil {
+		return nil, err
+	}
+
+	return ac.NewIntrospection(opts)
+}
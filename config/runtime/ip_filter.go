@@ -0,0 +1,38 @@
+package runtime
+
+import (
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config"
+)
+
+// newIPFilter creates the ip filter access control of the given configuration.
+func newIPFilter(ipFilterConf *config.IPFilter, log *logrus.Entry) (*ac.IPFilter, error) {
+	opts := &ac.IPFilterOptions{
+		Allow: ipFilterConf.Allow,
+		Deny:  ipFilterConf.Deny,
+		Log:   log,
+	}
+
+	for _, file := range []struct {
+		src    string
+		target *string
+	}{
+		{ipFilterConf.AllowFile, &opts.AllowFile},
+		{ipFilterConf.DenyFile, &opts.DenyFile},
+	} {
+		if file.src == "" {
+			continue
+		}
+		p, err := filepath.Abs(file.src)
+		if err != nil {
+			return nil, err
+		}
+		*file.target = p
+	}
+
+	return ac.NewIPFilter(opts)
+}
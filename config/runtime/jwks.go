@@ -0,0 +1,63 @@
+package runtime
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config"
+)
+
+// newJWKS creates the key set of the given jwt configuration. The jwks_url is requested
+// via the referenced backend or a backend with the origin of the jwks_url.
+func newJWKS(evalCtx *hcl.EvalContext, jwtConf *config.JWT, log *logrus.Entry, conf *config.Couper, backends *backendRegistry) (*ac.JWKS, error) {
+	if jwtConf.Key != "" || jwtConf.KeyFile != "" {
+		return nil, fmt.Errorf("key or key_file and jwks_url or jwks_file are mutually exclusive")
+	}
+
+	opts := &ac.JWKSOptions{URL: jwtConf.JWKSURL}
+
+	if err := parseDuration(jwtConf.JWKSTTL, &opts.TTL); err != nil {
+		return nil, fmt.Errorf("jwks_ttl: %v", err)
+	}
+
+	if jwtConf.JWKSFile != "" {
+		content, err := readFile(jwtConf.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		opts.File = content
+	}
+
+	if jwtConf.JWKSURL != "" {
+		u, err := url.Parse(jwtConf.JWKSURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid jwks_url: %q", jwtConf.JWKSURL)
+		}
+
+		backendCtx, err := newBackendBody(conf, jwtConf.BackendName, u, jwtConf.Name+"_jwks")
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.Backend, err = newBackend(evalCtx, backendCtx, log, conf, backends); err != nil {
+			return nil, err
+		}
+
+		// the key set requests are not bound to a client request, provide the
+		// configuration context for the backend evaluation instead
+		req, err := http.NewRequest(http.MethodGet, jwtConf.JWKSURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		opts.Context = conf.Context.WithClientRequest(req)
+	} else if jwtConf.BackendName != "" {
+		return nil, fmt.Errorf("backend requires a jwks_url")
+	}
+
+	return ac.NewJWKS(opts)
+}
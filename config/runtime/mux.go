@@ -1,6 +1,7 @@
 package runtime
 
 import (
+	"crypto/tls"
 	"net/http"
 
 	"github.com/avenga/couper/errors"
@@ -12,6 +13,12 @@ type MuxOptions struct {
 	SPARoutes      map[string]http.Handler
 	ErrorTpl       *errors.Template
 	Hosts          hosts
+	// TLSCertificates maps the configured host names to their certificates.
+	// A wildcard "*" entry is used as fallback. Empty for plain HTTP ports.
+	TLSCertificates map[string]*tls.Certificate
+	// TLSRequestClientCert enables the request for client certificates during the TLS handshake.
+	// The validation is up to the configured client_certificate access controls.
+	TLSRequestClientCert bool
 }
 
 func NewMuxOptions(errorTpl *errors.Template, hostsMap hosts) *MuxOptions {
@@ -20,10 +27,16 @@ func NewMuxOptions(errorTpl *errors.Template, hostsMap hosts) *MuxOptions {
 	}
 
 	return &MuxOptions{
-		EndpointRoutes: make(map[string]http.Handler),
-		FileRoutes:     make(map[string]http.Handler),
-		SPARoutes:      make(map[string]http.Handler),
-		ErrorTpl:       errorTpl,
-		Hosts:          hostsMap,
+		EndpointRoutes:  make(map[string]http.Handler),
+		FileRoutes:      make(map[string]http.Handler),
+		SPARoutes:       make(map[string]http.Handler),
+		ErrorTpl:        errorTpl,
+		Hosts:           hostsMap,
+		TLSCertificates: make(map[string]*tls.Certificate),
 	}
 }
+
+// IsTLS determines if the related port should terminate TLS connections.
+func (m *MuxOptions) IsTLS() bool {
+	return m != nil && len(m.TLSCertificates) > 0
+}
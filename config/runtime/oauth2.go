@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/config"
+	hclbody "github.com/avenga/couper/config/body"
+	"github.com/avenga/couper/handler/transport"
+)
+
+// newOAuth2 creates the token source of the given backend configuration. The token requests
+// are sent via the referenced backend or a backend with the origin of the token endpoint.
+func newOAuth2(evalCtx *hcl.EvalContext, beConf config.Backend, log *logrus.Entry, conf *config.Couper, backends *backendRegistry) (*transport.OAuth2, error) {
+	oauth2 := beConf.OAuth2
+
+	if beConf.BasicAuth != "" {
+		return nil, fmt.Errorf("backend %q: basic_auth and oauth2 are mutually exclusive", beConf.Name)
+	}
+
+	if oauth2.GrantType != "" && oauth2.GrantType != transport.GrantTypeClientCredentials {
+		return nil, fmt.Errorf("backend %q: oauth2: unsupported grant_type: %q", beConf.Name, oauth2.GrantType)
+	}
+
+	u, err := url.Parse(oauth2.TokenEndpoint)
+	if oauth2.BackendName == "" && (err != nil || u.Host == "") {
+		return nil, fmt.Errorf("backend %q: oauth2: invalid token_endpoint: %q", beConf.Name, oauth2.TokenEndpoint)
+	}
+
+	tokenBackendCtx, err := newBackendBody(conf, oauth2.BackendName, u, beConf.Name+"_oauth2")
+	if err != nil {
+		return nil, fmt.Errorf("backend %q: oauth2: %v", beConf.Name, err)
+	}
+
+	// prevent token request loops
+	tokenBeConf := &struct {
+		OAuth2 *config.OAuth2 `hcl:"oauth2,block"`
+		Remain hcl.Body       `hcl:",remain"`
+	}{}
+	if diags := gohcl.DecodeBody(tokenBackendCtx, evalCtx, tokenBeConf); diags.HasErrors() {
+		return nil, diags
+	}
+	if tokenBeConf.OAuth2 != nil {
+		return nil, fmt.Errorf("backend %q: oauth2: token backend %q must not configure oauth2", beConf.Name, oauth2.BackendName)
+	}
+
+	tokenBackend, err := newBackend(evalCtx, tokenBackendCtx, log, conf, backends)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenSource, err := transport.NewOAuth2(&transport.OAuth2Options{
+		AuthMethod:    oauth2.TokenEndpointAuthMethod,
+		ClientID:      oauth2.ClientID,
+		ClientSecret:  oauth2.ClientSecret,
+		Scope:         oauth2.Scope,
+		TokenEndpoint: oauth2.TokenEndpoint,
+	}, tokenBackend)
+	if err != nil {
+		return nil, fmt.Errorf("backend %q: %v", beConf.Name, err)
+	}
+	return tokenSource, nil
+}
+
+// newBackendBody returns the body of the referenced definitions backend or, without a reference,
+// the body of a backend named by the given name with the origin of the given url.
+func newBackendBody(conf *config.Couper, reference string, u *url.URL, name string) (hcl.Body, error) {
+	if reference != "" {
+		if conf.Definitions != nil {
+			for _, be := range conf.Definitions.Backend {
+				if be.Name == reference {
+					return be.Remain, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("backend reference is not defined: %q", reference)
+	}
+
+	return hclbody.New(&hcl.BodyContent{Attributes: map[string]*hcl.Attribute{
+		"name":   {Name: "name", Expr: &hclsyntax.LiteralValueExpr{Val: cty.StringVal(name)}},
+		"origin": {Name: "origin", Expr: &hclsyntax.LiteralValueExpr{Val: cty.StringVal(u.Scheme + "://" + u.Host)}},
+	}}), nil
+}
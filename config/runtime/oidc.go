@@ -0,0 +1,60 @@
+package runtime
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config"
+)
+
+// newOIDC creates the OpenID Connect access control of the given configuration. The IdP
+// requests are sent via the referenced backend or a backend with the origin of the configuration_url.
+func newOIDC(evalCtx *hcl.EvalContext, oidcConf *config.OIDC, log *logrus.Entry, conf *config.Couper, backends *backendRegistry) (*ac.OIDC, error) {
+	u, err := url.Parse(oidcConf.ConfigurationURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid configuration_url: %q", oidcConf.ConfigurationURL)
+	}
+
+	opts := &ac.OIDCOptions{
+		ClientID:         oidcConf.ClientID,
+		ClientSecret:     oidcConf.ClientSecret,
+		ConfigurationURL: oidcConf.ConfigurationURL,
+		CookieName:       oidcConf.CookieName,
+		Name:             oidcConf.Name,
+		RedirectURI:      oidcConf.RedirectURI,
+		Scope:            oidcConf.Scope,
+		SessionSecret:    []byte(oidcConf.SessionSecret),
+	}
+
+	if err = parseDuration(oidcConf.ConfigurationTTL, &opts.ConfigurationTTL); err != nil {
+		return nil, fmt.Errorf("configuration_ttl: %v", err)
+	}
+
+	if err = parseDuration(oidcConf.SessionTTL, &opts.SessionTTL); err != nil {
+		return nil, fmt.Errorf("session_ttl: %v", err)
+	}
+
+	backendCtx, err := newBackendBody(conf, oidcConf.BackendName, u, oidcConf.Name+"_oidc")
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Backend, err = newBackend(evalCtx, backendCtx, log, conf, backends); err != nil {
+		return nil, err
+	}
+
+	// the configuration and key set requests are not bound to a client request, provide
+	// the configuration context for the backend evaluation instead
+	req, err := http.NewRequest(http.MethodGet, oidcConf.ConfigurationURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = conf.Context.WithClientRequest(req)
+
+	return ac.NewOIDC(opts)
+}
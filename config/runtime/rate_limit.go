@@ -0,0 +1,82 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/config"
+)
+
+// configureRateLimits creates the rate limits of the given definitions by name.
+func configureRateLimits(definitions *config.Definitions, log *logrus.Entry) (ac.Map, error) {
+	rateLimits := make(ac.Map)
+	if definitions == nil {
+		return rateLimits, nil
+	}
+
+	for _, rateLimitConf := range definitions.RateLimit {
+		name := strings.TrimSpace(rateLimitConf.Name)
+		if name == "" {
+			return nil, fmt.Errorf("rate_limit: label required")
+		}
+
+		if _, exist := rateLimits[name]; exist {
+			return nil, fmt.Errorf("rate_limit: '%s' already exists", name)
+		}
+
+		var window time.Duration
+		if err := parseDuration(rateLimitConf.Window, &window); err != nil {
+			return nil, fmt.Errorf("loading rate_limit %q definition failed: window: %s", name, err)
+		}
+
+		// an omitted key results in a static null expression
+		key := rateLimitConf.Key
+		if key != nil && len(key.Variables()) == 0 {
+			if v, diags := key.Value(nil); !diags.HasErrors() && v.IsNull() {
+				key = nil
+			}
+		}
+
+		rateLimit, err := ac.NewRateLimit(&ac.RateLimitOptions{
+			Algorithm: rateLimitConf.Algorithm,
+			Key:       key,
+			Limit:     rateLimitConf.Limit,
+			Log:       log.WithField("rate_limit", name),
+			MaxKeys:   rateLimitConf.MaxKeys,
+			Name:      name,
+			Window:    window,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("loading rate_limit %q definition failed: %s", name, err)
+		}
+
+		rateLimits[name] = rateLimit
+	}
+	return rateLimits, nil
+}
+
+// newRateLimitList returns the referenced rate limits in the given order, each at most once.
+func newRateLimitList(rateLimits ac.Map, references ...[]string) (ac.List, error) {
+	var list ac.List
+	seen := make(map[string]bool)
+	for _, names := range references {
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			rateLimit, exist := rateLimits[name]
+			if !exist {
+				return nil, fmt.Errorf("rate_limit is not defined: %s", name)
+			}
+			list = append(list, rateLimit)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,91 @@
+package runtime
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/handler/producer"
+)
+
+var DefaultRetry = &config.Retry{
+	Attempts:    3,
+	Backoff:     "100ms",
+	MaxBackoff:  "2s",
+	Methods:     producer.IdempotentMethods,
+	StatusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+}
+
+// newRetry creates the retry options of a proxy or request block. Their retry
+// block takes precedence over the one of the related backend.
+func newRetry(evalCtx *hcl.EvalContext, backendCtx hcl.Body, retry *config.Retry) (*producer.Retry, error) {
+	if retry == nil {
+		backendRetry := &struct {
+			Retry  *config.Retry `hcl:"retry,block"`
+			Remain hcl.Body      `hcl:",remain"`
+		}{}
+		if diags := gohcl.DecodeBody(backendCtx, evalCtx, backendRetry); diags.HasErrors() {
+			return nil, diags
+		}
+		retry = backendRetry.Retry
+	}
+
+	if retry == nil {
+		return nil, nil
+	}
+
+	r := &producer.Retry{
+		Attempts:      retry.Attempts,
+		Methods:       make(map[string]bool),
+		NetworkErrors: true,
+		StatusCodes:   make(map[int]bool),
+	}
+
+	if r.Attempts < 1 {
+		r.Attempts = DefaultRetry.Attempts
+	}
+
+	if retry.NetworkErrors != nil {
+		r.NetworkErrors = *retry.NetworkErrors
+	}
+
+	methods := retry.Methods
+	if len(methods) == 0 {
+		methods = DefaultRetry.Methods
+	}
+	for _, method := range methods {
+		r.Methods[strings.ToUpper(method)] = true
+	}
+
+	statusCodes := retry.StatusCodes
+	if statusCodes == nil {
+		statusCodes = DefaultRetry.StatusCodes
+	}
+	for _, status := range statusCodes {
+		if status < 100 || status > 599 {
+			return nil, fmt.Errorf("retry: invalid status code: %d", status)
+		}
+		r.StatusCodes[status] = true
+	}
+
+	backoff, maxBackoff := retry.Backoff, retry.MaxBackoff
+	if backoff == "" {
+		backoff = DefaultRetry.Backoff
+	}
+	if maxBackoff == "" {
+		maxBackoff = DefaultRetry.MaxBackoff
+	}
+
+	if err := parseDuration(backoff, &r.Backoff); err != nil {
+		return nil, fmt.Errorf("retry: %v", err)
+	}
+	if err := parseDuration(maxBackoff, &r.MaxBackoff); err != nil {
+		return nil, fmt.Errorf("retry: %v", err)
+	}
+
+	return r, nil
+}
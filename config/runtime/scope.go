@@ -0,0 +1,79 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	ac "github.com/avenga/couper/accesscontrol"
+)
+
+// newRequiredScope evaluates the required_scopes attribute which is either a scope,
+// a list of scopes for all methods or an object with scopes per method. The "*" key
+// of an object applies to all methods without own entry.
+func newRequiredScope(evalCtx *hcl.EvalContext, expr hcl.Expression) (*ac.RequiredScope, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	val, diags := expr.Value(evalCtx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	if val.IsNull() {
+		return nil, nil
+	}
+
+	if !val.IsWhollyKnown() {
+		return nil, fmt.Errorf("%s: required_scopes: unknown value", expr.Range().String())
+	}
+
+	scopes := make(map[string][]string)
+	valType := val.Type()
+	if valType.IsObjectType() || valType.IsMapType() {
+		for method, v := range val.AsValueMap() {
+			list, err := toScopeList(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: required_scopes: %q: %s", expr.Range().String(), method, err)
+			}
+			if method != ac.AnyMethod {
+				method = strings.ToUpper(method)
+			}
+			scopes[method] = list
+		}
+	} else {
+		list, err := toScopeList(val)
+		if err != nil {
+			return nil, fmt.Errorf("%s: required_scopes: %s", expr.Range().String(), err)
+		}
+		scopes[ac.AnyMethod] = list
+	}
+
+	return ac.NewRequiredScope(scopes), nil
+}
+
+func toScopeList(val cty.Value) ([]string, error) {
+	if val.IsNull() {
+		return nil, fmt.Errorf("expected a string or a list of strings")
+	}
+
+	valType := val.Type()
+	switch {
+	case valType == cty.String:
+		return []string{val.AsString()}, nil
+	case valType.IsListType() || valType.IsTupleType():
+		var list []string
+		for _, v := range val.AsValueSlice() {
+			if v.IsNull() || v.Type() != cty.String {
+				return nil, fmt.Errorf("scope must be a string")
+			}
+			list = append(list, v.AsString())
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("expected a string or a list of strings")
+	}
+}
@@ -66,6 +66,10 @@ type endpointMap map[*config.Endpoint]*config.API
 func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfiguration, error) {
 	defaultPort := conf.Settings.DefaultPort
 
+	if err := configureJWTSigningProfiles(conf); err != nil {
+		return nil, err
+	}
+
 	// confCtx is created to evaluate request / response related configuration errors on start.
 	noopReq := httptest.NewRequest(http.MethodGet, "https://couper.io", nil)
 	noopResp := httptest.NewRecorder().Result()
@@ -77,7 +81,15 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 		return nil, err
 	}
 
-	accessControls, err := configureAccessControls(conf, confCtx)
+	// backends shares the state of the definitions backends between their use sites
+	backends := newBackendRegistry(conf.Definitions)
+
+	accessControls, err := configureAccessControls(conf, confCtx, log, backends)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimits, err := configureRateLimits(conf.Definitions, log)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +103,10 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 		}
 	}
 
+	if err = configureTLS(conf, serverConfiguration, defaultPort); err != nil {
+		return nil, err
+	}
+
 	endpointHandlers := make(map[*config.Endpoint]http.Handler)
 
 	for _, srvConf := range conf.Servers {
@@ -99,6 +115,11 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 			return nil, err
 		}
 
+		srvRateLimits, err := newRateLimitList(rateLimits, srvConf.RateLimit)
+		if err != nil {
+			return nil, err
+		}
+
 		var spaHandler http.Handler
 		if srvConf.Spa != nil {
 			spaHandler, err = handler.NewSpa(srvConf.Spa.BootstrapFile, serverOptions)
@@ -106,9 +127,16 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 				return nil, err
 			}
 
+			spaAC := config.NewAccessControl(srvConf.AccessControl, srvConf.DisableAccessControl).
+				Merge(config.NewAccessControl(srvConf.Spa.AccessControl, srvConf.Spa.DisableAccessControl))
+			if err = checkUnbufferedBody(conf.Definitions, "spa", spaAC); err != nil {
+				return nil, err
+			}
+
 			spaHandler = configureProtectedHandler(accessControls, serverOptions.ServerErrTpl,
 				config.NewAccessControl(srvConf.AccessControl, srvConf.DisableAccessControl),
-				config.NewAccessControl(srvConf.Spa.AccessControl, srvConf.Spa.DisableAccessControl), spaHandler)
+				config.NewAccessControl(srvConf.Spa.AccessControl, srvConf.Spa.DisableAccessControl), spaHandler,
+				srvRateLimits)
 
 			for _, spaPath := range srvConf.Spa.Paths {
 				err = setRoutesFromHosts(serverConfiguration, serverOptions.ServerErrTpl, defaultPort, srvConf.Hosts, path.Join(serverOptions.SPABasePath, spaPath), spaHandler, spa)
@@ -124,9 +152,16 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 				return nil, err
 			}
 
+			filesAC := config.NewAccessControl(srvConf.AccessControl, srvConf.DisableAccessControl).
+				Merge(config.NewAccessControl(srvConf.Files.AccessControl, srvConf.Files.DisableAccessControl))
+			if err = checkUnbufferedBody(conf.Definitions, "files", filesAC); err != nil {
+				return nil, err
+			}
+
 			protectedFileHandler := configureProtectedHandler(accessControls, serverOptions.FileErrTpl,
 				config.NewAccessControl(srvConf.AccessControl, srvConf.DisableAccessControl),
-				config.NewAccessControl(srvConf.Files.AccessControl, srvConf.Files.DisableAccessControl), fileHandler)
+				config.NewAccessControl(srvConf.Files.AccessControl, srvConf.Files.DisableAccessControl), fileHandler,
+				srvRateLimits)
 
 			err = setRoutesFromHosts(serverConfiguration, serverOptions.ServerErrTpl, defaultPort, srvConf.Hosts, serverOptions.FileBasePath, protectedFileHandler, files)
 			if err != nil {
@@ -157,6 +192,32 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 			}
 			endpointsPatterns[cleanPattern] = true
 
+			// the scopes of the api and the endpoint are checked after all access controls
+			scopeExprs := []hcl.Expression{endpointConf.RequiredScopes}
+			if parentAPI != nil {
+				scopeExprs = append([]hcl.Expression{parentAPI.RequiredScopes}, scopeExprs...)
+			}
+
+			var scopes ac.List
+			for _, expr := range scopeExprs {
+				requiredScope, serr := newRequiredScope(confCtx, expr)
+				if serr != nil {
+					return nil, serr
+				}
+				if requiredScope != nil {
+					scopes = append(scopes, requiredScope)
+				}
+			}
+
+			rateLimitRefs := [][]string{srvConf.RateLimit}
+			if parentAPI != nil {
+				rateLimitRefs = append(rateLimitRefs, parentAPI.RateLimit)
+			}
+			endpointRateLimits, err := newRateLimitList(rateLimits, append(rateLimitRefs, endpointConf.RateLimit)...)
+			if err != nil {
+				return nil, err
+			}
+
 			// setACHandlerFn individual wrap for access_control configuration per endpoint
 			setACHandlerFn := func(protectedHandler http.Handler) {
 				accessControl := config.NewAccessControl(srvConf.AccessControl, srvConf.DisableAccessControl)
@@ -167,7 +228,7 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 
 				endpointHandlers[endpointConf] = configureProtectedHandler(accessControls, errTpl, accessControl,
 					config.NewAccessControl(endpointConf.AccessControl, endpointConf.DisableAccessControl),
-					protectedHandler)
+					protectedHandler, endpointRateLimits, scopes...)
 			}
 
 			var response *producer.Response
@@ -182,23 +243,32 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 			//var redirect producer.Redirect
 
 			for _, proxyConf := range endpointConf.Proxies {
-				backend, berr := newBackend(confCtx, proxyConf.Backend, log, conf.Settings.NoProxyFromEnv)
+				backend, berr := newBackend(confCtx, proxyConf.Backend, log, conf, backends)
 				if berr != nil {
 					return nil, berr
 				}
+				retry, rerr := newRetry(confCtx, proxyConf.Backend, proxyConf.Retry)
+				if rerr != nil {
+					return nil, rerr
+				}
 				proxyHandler := handler.NewProxy(backend, proxyConf.HCLBody())
 				p := &producer.Proxy{
 					Name:      proxyConf.Name,
+					Retry:     retry,
 					RoundTrip: proxyHandler,
 				}
 				proxies = append(proxies, p)
 			}
 
 			for _, requestConf := range endpointConf.Requests {
-				backend, berr := newBackend(confCtx, requestConf.Backend, log, conf.Settings.NoProxyFromEnv)
+				backend, berr := newBackend(confCtx, requestConf.Backend, log, conf, backends)
 				if berr != nil {
 					return nil, berr
 				}
+				retry, rerr := newRetry(confCtx, requestConf.Backend, requestConf.Retry)
+				if rerr != nil {
+					return nil, rerr
+				}
 				method := http.MethodGet
 				if requestConf.Method != "" {
 					method = requestConf.Method
@@ -209,6 +279,7 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 					Context: requestConf.Remain,
 					Method:  method,
 					Name:    requestConf.Name,
+					Retry:   retry,
 				})
 			}
 
@@ -270,7 +341,7 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry) (ServerConfi
 	return serverConfiguration, nil
 }
 
-func newBackend(evalCtx *hcl.EvalContext, backendCtx hcl.Body, log *logrus.Entry, ignoreProxyEnv bool) (http.RoundTripper, error) {
+func newBackend(evalCtx *hcl.EvalContext, backendCtx hcl.Body, log *logrus.Entry, conf *config.Couper, backends *backendRegistry) (http.RoundTripper, error) {
 	beConf := *DefaultBackendConf
 	if diags := gohcl.DecodeBody(backendCtx, evalCtx, &beConf); diags.HasErrors() {
 		return nil, diags
@@ -289,7 +360,7 @@ func newBackend(evalCtx *hcl.EvalContext, backendCtx hcl.Body, log *logrus.Entry
 		DisableCertValidation:  beConf.DisableCertValidation,
 		DisableConnectionReuse: beConf.DisableConnectionReuse,
 		HTTP2:                  beConf.HTTP2,
-		NoProxyFromEnv:         ignoreProxyEnv,
+		NoProxyFromEnv:         conf.Settings.NoProxyFromEnv,
 		Proxy:                  beConf.Proxy,
 		MaxConnections:         beConf.MaxConnections,
 	}
@@ -311,16 +382,118 @@ func newBackend(evalCtx *hcl.EvalContext, backendCtx hcl.Body, log *logrus.Entry
 		return nil, err
 	}
 
+	balancer, err := newSharedBalancer(beConf, backends)
+	if err != nil {
+		return nil, err
+	}
+
+	if beConf.Health != nil {
+		balancer, err = configureHealthCheck(evalCtx, beConf, balancer, tc, conf.Context, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	circuitBreaker, err := newCircuitBreaker(beConf, backends, log)
+	if err != nil {
+		return nil, err
+	}
+
+	throttle, err := newThrottle(beConf, backends)
+	if err != nil {
+		return nil, err
+	}
+
+	var oauth2 *transport.OAuth2
+	if beConf.OAuth2 != nil {
+		// all use sites of a definitions backend share the cached token
+		tokenSource, oerr := backends.get(beConf.Name, "oauth2", fmt.Sprintf("%+v", *beConf.OAuth2), func() (interface{}, error) {
+			return newOAuth2(evalCtx, beConf, log, conf, backends)
+		})
+		if oerr != nil {
+			return nil, oerr
+		}
+		oauth2 = tokenSource.(*transport.OAuth2)
+	}
+
 	options := &transport.BackendOptions{
-		BasicAuth:  beConf.BasicAuth,
-		OpenAPI:    openAPIopts,
-		PathPrefix: beConf.PathPrefix,
+		Balancer:       balancer,
+		BasicAuth:      beConf.BasicAuth,
+		CircuitBreaker: circuitBreaker,
+		OAuth2:         oauth2,
+		OpenAPI:        openAPIopts,
+		Throttle:       throttle,
 	}
 	backend := transport.NewBackend(backendCtx, tc, options, log)
 
 	return backend, nil
 }
 
+// newSharedBalancer returns the load balancer of the given backend configuration which is
+// shared by all use sites of a definitions backend.
+func newSharedBalancer(beConf config.Backend, backends *backendRegistry) (*transport.Balancer, error) {
+	lb := beConf.LoadBalancer
+	if lb == nil {
+		return nil, nil
+	}
+
+	key := lb.Strategy
+	for _, upstream := range lb.Upstreams {
+		key += fmt.Sprintf("|%s:%d", upstream.Origin, upstream.Weight)
+	}
+	if lb.HashKey != nil {
+		key += "|" + lb.HashKey.Range().String()
+	}
+
+	balancer, err := backends.get(beConf.Name, "load_balancer", key, func() (interface{}, error) {
+		return newBalancer(beConf)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balancer.(*transport.Balancer), nil
+}
+
+// newBalancer creates the load balancer for the configured upstreams, if any.
+func newBalancer(beConf config.Backend) (*transport.Balancer, error) {
+	lb := beConf.LoadBalancer
+	if lb == nil {
+		return nil, nil
+	}
+
+	content, _, _ := beConf.Remain.PartialContent(&hcl.BodySchema{Attributes: []hcl.AttributeSchema{
+		{Name: "origin"}},
+	})
+	if content != nil {
+		if _, exist := content.Attributes["origin"]; exist {
+			return nil, fmt.Errorf("backend %q: origin and load_balancer are mutually exclusive", beConf.Name)
+		}
+	}
+
+	var origins []*transport.Origin
+	for _, upstream := range lb.Upstreams {
+		origin, err := transport.NewOrigin(upstream.Origin, upstream.Weight)
+		if err != nil {
+			return nil, fmt.Errorf("backend %q: load_balancer: %v", beConf.Name, err)
+		}
+		origins = append(origins, origin)
+	}
+
+	// an omitted hash_key results in a static null expression
+	hashKey := lb.HashKey
+	if hashKey != nil && len(hashKey.Variables()) == 0 {
+		if v, diags := hashKey.Value(nil); !diags.HasErrors() && v.IsNull() {
+			hashKey = nil
+		}
+	}
+
+	balancer, err := transport.NewBalancer(lb.Strategy, hashKey, origins)
+	if err != nil {
+		return nil, fmt.Errorf("backend %q: %v", beConf.Name, err)
+	}
+	return balancer, nil
+}
+
 func getBackendName(evalCtx *hcl.EvalContext, backendCtx hcl.Body) (string, error) {
 	content, _, _ := backendCtx.PartialContent(&hcl.BodySchema{Attributes: []hcl.AttributeSchema{
 		{Name: "name"}},
@@ -363,17 +536,31 @@ func splitWildcardHostPort(host string, configuredPort int) (string, Port, error
 	return ho, Port(po), nil
 }
 
-func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext) (ac.Map, error) {
+func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log *logrus.Entry, backends *backendRegistry) (ac.Map, error) {
 	accessControls := make(ac.Map)
 
 	if conf.Definitions != nil {
+		for _, apiKeyConf := range conf.Definitions.APIKey {
+			name, err := validateACName(accessControls, apiKeyConf.Name, "api_key")
+			if err != nil {
+				return nil, err
+			}
+
+			apiKey, err := newAPIKey(apiKeyConf, log)
+			if err != nil {
+				return nil, fmt.Errorf("loading api_key %q definition failed: %s", name, err)
+			}
+
+			accessControls[name] = ac.ValidateFunc(apiKey.Validate)
+		}
+
 		for _, ba := range conf.Definitions.BasicAuth {
 			name, err := validateACName(accessControls, ba.Name, "basic_auth")
 			if err != nil {
 				return nil, err
 			}
 
-			basicAuth, err := ac.NewBasicAuth(name, ba.User, ba.Pass, ba.File, ba.Realm)
+			basicAuth, err := ac.NewBasicAuth(name, ba.User, ba.Pass, ba.File, ba.Realm, log)
 			if err != nil {
 				return nil, err
 			}
@@ -381,28 +568,47 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext) (ac.
 			accessControls[name] = ac.ValidateFunc(basicAuth.Validate)
 		}
 
+		for _, cc := range conf.Definitions.ClientCertificate {
+			name, err := validateACName(accessControls, cc.Name, "client_certificate")
+			if err != nil {
+				return nil, err
+			}
+
+			caBundle, err := readFile(cc.CAFile)
+			if err != nil {
+				return nil, err
+			}
+
+			var crl []byte
+			if cc.CRLFile != "" {
+				if crl, err = readFile(cc.CRLFile); err != nil {
+					return nil, err
+				}
+			}
+
+			clientCert, err := ac.NewClientCertificate(name, caBundle, crl,
+				cc.AllowedCommonNames, cc.AllowedDNSNames, cc.AllowedEmailAddresses)
+			if err != nil {
+				return nil, fmt.Errorf("loading client_certificate %q definition failed: %s", name, err)
+			}
+
+			accessControls[name] = ac.ValidateFunc(clientCert.Validate)
+		}
+
 		for _, jwt := range conf.Definitions.JWT {
 			name, err := validateACName(accessControls, jwt.Name, "jwt")
 			if err != nil {
 				return nil, err
 			}
 
-			var jwtSource ac.Source
-			var jwtKey string
-			if jwt.Cookie != "" {
-				jwtSource = ac.Cookie
-				jwtKey = jwt.Cookie
-			} else if jwt.Header != "" {
-				jwtSource = ac.Header
-				jwtKey = jwt.Header
+			jwtSource, jwtKey, err := newJWTSource(jwt)
+			if err != nil {
+				return nil, fmt.Errorf("loading jwt %q definition failed: %s", name, err)
 			}
+
 			var key []byte
 			if jwt.KeyFile != "" {
-				p, err := filepath.Abs(jwt.KeyFile)
-				if err != nil {
-					return nil, err
-				}
-				content, err := ioutil.ReadFile(p)
+				content, err := readFile(jwt.KeyFile)
 				if err != nil {
 					return nil, err
 				}
@@ -411,33 +617,283 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext) (ac.
 				key = []byte(jwt.Key)
 			}
 
+			// claims with variable references are evaluated per request
+			dynamicClaims := jwt.Claims != nil && len(jwt.Claims.Variables()) > 0
+
 			var claims map[string]interface{}
-			if jwt.Claims != nil {
+			if jwt.Claims != nil && !dynamicClaims {
 				c, diags := seetie.ExpToMap(confCtx, jwt.Claims)
 				if diags.HasErrors() {
 					return nil, diags
 				}
 				claims = c
 			}
-			j, err := ac.NewJWT(jwt.SignatureAlgorithm, name, claims, jwt.ClaimsRequired, jwtSource, jwtKey, key)
+			var j *ac.JWT
+			if jwt.JWKSURL != "" || jwt.JWKSFile != "" {
+				jwks, jerr := newJWKS(confCtx, jwt, log, conf, backends)
+				if jerr != nil {
+					return nil, fmt.Errorf("loading jwt %q definition failed: %s", name, jerr)
+				}
+				j, err = ac.NewJWTFromJWKS(jwt.SignatureAlgorithm, name, claims, jwt.ClaimsRequired, jwtSource, jwtKey, jwks)
+			} else if jwt.SignatureAlgorithm == "" {
+				return nil, fmt.Errorf("loading jwt %q definition failed: missing signature_algorithm", name)
+			} else {
+				j, err = ac.NewJWT(jwt.SignatureAlgorithm, name, claims, jwt.ClaimsRequired, jwtSource, jwtKey, key)
+			}
 			if err != nil {
 				return nil, fmt.Errorf("loading jwt %q definition failed: %s", name, err)
 			}
 
+			if dynamicClaims {
+				j.SetClaimsExpression(jwt.Claims)
+			}
+
+			if jwt.StripToken {
+				j.StripToken()
+			}
+
+			j.SetScopeClaims(jwt.ScopeClaim, jwt.RolesClaim)
+			j.SetRealm(jwt.Realm)
+
 			accessControls[name] = ac.ValidateFunc(j.Validate)
 		}
+
+		for _, introspectionConf := range conf.Definitions.Introspection {
+			name, err := validateACName(accessControls, introspectionConf.Name, "introspection")
+			if err != nil {
+				return nil, err
+			}
+
+			introspection, err := newIntrospection(confCtx, introspectionConf, log, conf, backends)
+			if err != nil {
+				return nil, fmt.Errorf("loading introspection %q definition failed: %s", name, err)
+			}
+
+			accessControls[name] = ac.ValidateFunc(introspection.Validate)
+		}
+
+		for _, ipFilterConf := range conf.Definitions.IPFilter {
+			name, err := validateACName(accessControls, ipFilterConf.Name, "ip_filter")
+			if err != nil {
+				return nil, err
+			}
+
+			ipFilter, err := newIPFilter(ipFilterConf, log)
+			if err != nil {
+				return nil, fmt.Errorf("loading ip_filter %q definition failed: %s", name, err)
+			}
+
+			accessControls[name] = ac.ValidateFunc(ipFilter.Validate)
+		}
+
+		for _, oidcConf := range conf.Definitions.OIDC {
+			name, err := validateACName(accessControls, oidcConf.Name, "oidc")
+			if err != nil {
+				return nil, err
+			}
+
+			oidc, err := newOIDC(confCtx, oidcConf, log, conf, backends)
+			if err != nil {
+				return nil, fmt.Errorf("loading oidc %q definition failed: %s", name, err)
+			}
+
+			accessControls[name] = ac.ValidateFunc(oidc.Validate)
+		}
+
+		// record the granting access controls for the access log, groups are composed of them
+		errorFiles := accessControlErrorFiles(conf.Definitions)
+		for name, control := range accessControls {
+			var errTpl *errors.Template
+			if file := errorFiles[name]; file != "" {
+				tpl, err := errors.NewTemplateFromFile(file)
+				if err != nil {
+					return nil, fmt.Errorf("access control %q: error_file: %s", name, err)
+				}
+				errTpl = tpl
+			}
+			accessControls[name] = ac.NewNamed(name, control, errTpl)
+		}
+
+		if err := configureAccessControlGroups(conf.Definitions.AccessControlGroup, accessControls); err != nil {
+			return nil, err
+		}
 	}
 
 	return accessControls, nil
 }
 
-func configureProtectedHandler(m ac.Map, errTpl *errors.Template, parentAC, handlerAC config.AccessControl, h http.Handler) http.Handler {
-	var acList ac.List
+// accessControlErrorFiles returns the configured error files by access control name.
+func accessControlErrorFiles(definitions *config.Definitions) map[string]string {
+	files := make(map[string]string)
+	for _, c := range definitions.APIKey {
+		files[c.Name] = c.ErrorFile
+	}
+	for _, c := range definitions.BasicAuth {
+		files[c.Name] = c.ErrorFile
+	}
+	for _, c := range definitions.ClientCertificate {
+		files[c.Name] = c.ErrorFile
+	}
+	for _, c := range definitions.Introspection {
+		files[c.Name] = c.ErrorFile
+	}
+	for _, c := range definitions.IPFilter {
+		files[c.Name] = c.ErrorFile
+	}
+	for _, c := range definitions.JWT {
+		files[c.Name] = c.ErrorFile
+	}
+	for _, c := range definitions.OIDC {
+		files[c.Name] = c.ErrorFile
+	}
+	return files
+}
+
+// newJWTSource returns the token source of the given jwt configuration. Exactly one source is allowed.
+func newJWTSource(jwt *config.JWT) (ac.Source, string, error) {
+	source, key := ac.Unknown, ""
+	for _, src := range []struct {
+		key    string
+		source ac.Source
+	}{
+		{jwt.Cookie, ac.Cookie},
+		{jwt.Header, ac.Header},
+		{jwt.QueryParam, ac.QueryParam},
+		{jwt.PostParam, ac.PostParam},
+	} {
+		if src.key == "" {
+			continue
+		}
+		if source != ac.Unknown {
+			return ac.Unknown, "", fmt.Errorf("only one of cookie, header, query_param or post_param is allowed")
+		}
+		source, key = src.source, src.key
+	}
+
+	if source == ac.Unknown {
+		return ac.Unknown, "", fmt.Errorf("one of cookie, header, query_param or post_param is required")
+	}
+
+	if jwt.StripToken && source != ac.QueryParam && source != ac.PostParam {
+		return ac.Unknown, "", fmt.Errorf("strip_token requires query_param or post_param")
+	}
+	return source, key, nil
+}
+
+// checkUnbufferedBody rejects jwt access controls with a post_param, also within groups, for
+// the given block. Only endpoints buffer the request body, e.g. a spa or files block does not.
+func checkUnbufferedBody(definitions *config.Definitions, block string, accessControl config.AccessControl) error {
+	if definitions == nil {
+		return nil
+	}
+
+	groups := make(map[string]*config.AccessControlGroup)
+	for _, groupConf := range definitions.AccessControlGroup {
+		groups[strings.TrimSpace(groupConf.Name)] = groupConf
+	}
+
+	visited := make(map[string]bool)
+	var check func(names []string) error
+	check = func(names []string) error {
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			if visited[name] {
+				continue
+			}
+			visited[name] = true
+
+			if groupConf, exist := groups[name]; exist {
+				if err := check(append(groupConf.All, groupConf.Any...)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, jwtConf := range definitions.JWT {
+				if strings.TrimSpace(jwtConf.Name) == name && jwtConf.PostParam != "" {
+					return fmt.Errorf("%s: jwt %q: post_param is not supported, the request body is read by endpoints only", block, name)
+				}
+			}
+		}
+		return nil
+	}
+	return check(accessControl.List())
+}
+
+// configureJWTSigningProfiles registers the signing profiles for the jwt_sign() function.
+func configureJWTSigningProfiles(conf *config.Couper) error {
+	if conf.Definitions == nil {
+		return nil
+	}
+
+	names := make(map[string]struct{})
+	for _, profile := range conf.Definitions.JWTSigningProfile {
+		if _, exist := names[profile.Name]; exist {
+			return fmt.Errorf("jwt_signing_profile %q: duplicate definition", profile.Name)
+		}
+		names[profile.Name] = struct{}{}
+
+		var key []byte
+		if profile.KeyFile != "" {
+			content, err := readFile(profile.KeyFile)
+			if err != nil {
+				return err
+			}
+			key = content
+		} else if profile.Key != "" {
+			key = []byte(profile.Key)
+		}
+
+		var ttl time.Duration
+		if err := parseDuration(profile.TTL, &ttl); err != nil {
+			return fmt.Errorf("jwt_signing_profile %q: %v", profile.Name, err)
+		}
+
+		signer, err := ac.NewJWTSigner(profile.SignatureAlgorithm, key, ttl)
+		if err != nil {
+			return fmt.Errorf("loading jwt_signing_profile %q definition failed: %s", profile.Name, err)
+		}
+
+		conf.Context.AddJWTSigningProfile(profile.Name, &eval.JWTSigningProfile{
+			Claims: profile.Claims,
+			Signer: signer,
+		})
+	}
+
+	return nil
+}
+
+// readFile reads the content of the given file path relative to the working directory.
+func readFile(file string) ([]byte, error) {
+	p, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(p)
+}
+
+// configureProtectedHandler wraps the given handler with the referenced access controls,
+// followed by the given required scopes. Rate limits per client address are checked first
+// to count requests with failing access controls too. Rate limits with a key expression are
+// checked last since their key may refer to access control variables.
+func configureProtectedHandler(m ac.Map, errTpl *errors.Template, parentAC, handlerAC config.AccessControl, h http.Handler,
+	rateLimits ac.List, scopes ...ac.AccessControl) http.Handler {
+	var acList, keyedRateLimits ac.List
+	for _, control := range rateLimits {
+		if rateLimit, ok := control.(*ac.RateLimit); ok && rateLimit.ClientKeyed() {
+			acList = append(acList, control)
+		} else {
+			keyedRateLimits = append(keyedRateLimits, control)
+		}
+	}
+
 	for _, acName := range parentAC.
 		Merge(handlerAC).List() {
 		m.MustExist(acName)
 		acList = append(acList, m[acName])
 	}
+	acList = append(acList, scopes...)
+	acList = append(acList, keyedRateLimits...)
 	if len(acList) > 0 {
 		return handler.NewAccessControl(h, errTpl, acList...)
 	}
@@ -1,9 +1,14 @@
 package runtime
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"testing"
 
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	ac "github.com/avenga/couper/accesscontrol"
 	"github.com/avenga/couper/config"
 )
 
@@ -210,3 +215,131 @@ func TestServer_getEndpointsList(t *testing.T) {
 		}
 	}
 }
+
+func TestServer_newJWTSource(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		conf    *config.JWT
+		source  ac.Source
+		key     string
+		wantErr bool
+	}{
+		{"cookie", &config.JWT{Cookie: "token"}, ac.Cookie, "token", false},
+		{"header", &config.JWT{Header: "Authorization"}, ac.Header, "Authorization", false},
+		{"query_param", &config.JWT{QueryParam: "access_token"}, ac.QueryParam, "access_token", false},
+		{"post_param", &config.JWT{PostParam: "access_token", StripToken: true}, ac.PostParam, "access_token", false},
+		{"none", &config.JWT{}, ac.Unknown, "", true},
+		{"multiple", &config.JWT{Header: "Authorization", QueryParam: "access_token"}, ac.Unknown, "", true},
+		{"strip_token /w header", &config.JWT{Header: "Authorization", StripToken: true}, ac.Unknown, "", true},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			source, key, err := newJWTSource(tc.conf)
+			if (err != nil) != tc.wantErr {
+				subT.Fatalf("unexpected error: %v", err)
+			}
+			if source != tc.source || key != tc.key {
+				subT.Errorf("want source %d with key %q, got: %d, %q", tc.source, tc.key, source, key)
+			}
+		})
+	}
+}
+
+func TestServer_configureAccessControlGroups(t *testing.T) {
+	type group = config.AccessControlGroup
+
+	for _, tc := range []struct {
+		name    string
+		groups  []*config.AccessControlGroup
+		wantErr string
+	}{
+		{"nested", []*group{{Name: "outer", Any: []string{"inner", "ac"}}, {Name: "inner", All: []string{"ac"}}}, ""},
+		{"undefined", []*group{{Name: "g", Any: []string{"missing"}}}, "access control is not defined: missing"},
+		{"circular", []*group{{Name: "a", Any: []string{"b"}}, {Name: "b", All: []string{"a"}}}, `access_control_group "a": circular reference`},
+		{"all and any", []*group{{Name: "g", All: []string{"ac"}, Any: []string{"ac"}}}, `access_control_group "g": either all or any must be specified`},
+		{"empty", []*group{{Name: "g"}}, `access_control_group "g": either all or any must be specified`},
+		{"duplicate", []*group{{Name: "g", All: []string{"ac"}}, {Name: "g", All: []string{"ac"}}}, "access control: 'g' already exists"},
+		{"existing", []*group{{Name: "ac", All: []string{"ac"}}}, "access control: 'ac' already exists"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			accessControls := ac.Map{"ac": ac.ValidateFunc(func(_ *http.Request) error { return nil })}
+
+			err := configureAccessControlGroups(tc.groups, accessControls)
+			if err == nil && tc.wantErr != "" || err != nil && err.Error() != tc.wantErr {
+				subT.Fatalf("want error %q, got: %v", tc.wantErr, err)
+			}
+
+			if err == nil {
+				for _, g := range tc.groups {
+					accessControls.MustExist(g.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_checkUnbufferedBody(t *testing.T) {
+	definitions := &config.Definitions{
+		AccessControlGroup: []*config.AccessControlGroup{
+			{Name: "outer", Any: []string{"inner", "header"}},
+			{Name: "inner", All: []string{"form"}},
+		},
+		JWT: []*config.JWT{
+			{Name: "form", PostParam: "access_token"},
+			{Name: "header", Header: "Authorization"},
+		},
+	}
+
+	for _, tc := range []struct {
+		name          string
+		accessControl config.AccessControl
+		wantErr       string
+	}{
+		{"header", config.NewAccessControl([]string{"header"}, nil), ""},
+		{"post_param", config.NewAccessControl([]string{"header", "form"}, nil), `spa: jwt "form": post_param is not supported, the request body is read by endpoints only`},
+		{"group", config.NewAccessControl([]string{"outer"}, nil), `spa: jwt "form": post_param is not supported, the request body is read by endpoints only`},
+		{"disabled", config.NewAccessControl([]string{"form"}, []string{"form"}), ""},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			err := checkUnbufferedBody(definitions, "spa", tc.accessControl)
+			if err == nil && tc.wantErr != "" || err != nil && err.Error() != tc.wantErr {
+				subT.Errorf("want error %q, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestServer_configureRateLimits(t *testing.T) {
+	type rateLimit = config.RateLimit
+
+	for _, tc := range []struct {
+		name       string
+		rateLimits []*config.RateLimit
+		references [][]string
+		wantLen    int
+		wantErr    string
+	}{
+		{"merged", []*rateLimit{{Name: "a", Limit: 1, Window: "1s"}, {Name: "b", Limit: 1, Window: "1m"}}, [][]string{{"a"}, {"b", "a"}}, 2, ""},
+		{"undefined", []*rateLimit{{Name: "a", Limit: 1, Window: "1s"}}, [][]string{{"missing"}}, 0, "rate_limit is not defined: missing"},
+		{"duplicate", []*rateLimit{{Name: "a", Limit: 1, Window: "1s"}, {Name: "a", Limit: 2, Window: "1s"}}, nil, 0, "rate_limit: 'a' already exists"},
+		{"window", []*rateLimit{{Name: "a", Limit: 1, Window: "-1s"}}, nil, 0, `loading rate_limit "a" definition failed: rate_limit: window must be greater than 0`},
+		{"limit", []*rateLimit{{Name: "a", Window: "1s"}}, nil, 0, `loading rate_limit "a" definition failed: rate_limit: limit must be greater than 0`},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			logger, _ := logrustest.NewNullLogger()
+			rateLimits, err := configureRateLimits(&config.Definitions{RateLimit: tc.rateLimits}, logger.WithContext(context.Background()))
+
+			var list ac.List
+			if err == nil {
+				list, err = newRateLimitList(rateLimits, tc.references...)
+			}
+
+			if err == nil && tc.wantErr != "" || err != nil && err.Error() != tc.wantErr {
+				subT.Fatalf("want error %q, got: %v", tc.wantErr, err)
+			}
+
+			if len(list) != tc.wantLen {
+				subT.Errorf("want %d rate limits, got: %d", tc.wantLen, len(list))
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/handler/transport"
+)
+
+var DefaultThrottle = &config.Throttle{
+	MaxQueue: 100,
+	MaxWait:  "10s",
+}
+
+// newThrottle returns the throttle of the given backend configuration, if any. All use
+// sites of a definitions backend share one throttle and so its rate.
+func newThrottle(beConf config.Backend, backends *backendRegistry) (*transport.Throttle, error) {
+	opts, err := newThrottleOptions(beConf.Throttle)
+	if err != nil {
+		return nil, fmt.Errorf("backend %q: throttle: %v", beConf.Name, err)
+	}
+
+	if opts == nil {
+		return nil, nil
+	}
+
+	throttle, err := backends.get(beConf.Name, "throttle", fmt.Sprintf("%+v", *opts), func() (interface{}, error) {
+		return transport.NewThrottle(opts), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return throttle.(*transport.Throttle), nil
+}
+
+func newThrottleOptions(t *config.Throttle) (*transport.ThrottleOptions, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	if t.RequestsPerSecond <= 0 {
+		return nil, fmt.Errorf("requests_per_second must be greater than 0: %v", t.RequestsPerSecond)
+	}
+
+	opts := &transport.ThrottleOptions{
+		Burst:             t.Burst,
+		MaxQueue:          t.MaxQueue,
+		RequestsPerSecond: t.RequestsPerSecond,
+	}
+
+	// the default burst allows the requests of one second at once
+	if opts.Burst < 1 {
+		opts.Burst = int(math.Max(1, math.Ceil(t.RequestsPerSecond)))
+	}
+	if opts.MaxQueue < 1 {
+		opts.MaxQueue = DefaultThrottle.MaxQueue
+	}
+
+	maxWait := t.MaxWait
+	if maxWait == "" {
+		maxWait = DefaultThrottle.MaxWait
+	}
+
+	if err := parseDuration(maxWait, &opts.MaxWait); err != nil {
+		return nil, err
+	}
+
+	return opts, nil
+}
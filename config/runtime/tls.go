@@ -0,0 +1,114 @@
+package runtime
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/avenga/couper/config"
+)
+
+// configureTLS loads the configured server certificates and maps them to the
+// listen ports and hosts of their server block. Since a listener terminates
+// TLS for all of its hosts a port can not mix TLS and plain HTTP servers.
+func configureTLS(conf *config.Couper, srvConf ServerConfiguration, defaultPort int) error {
+	tlsPorts := make(map[Port]bool)
+	requestClientCert := conf.Definitions != nil && len(conf.Definitions.ClientCertificate) > 0
+
+	for _, srv := range conf.Servers {
+		hostList := srv.Hosts
+		if len(hostList) == 0 {
+			hostList = []string{"*"}
+		}
+
+		defaultCert, hostCerts, err := loadServerCertificates(srv, hostList, defaultPort)
+		if err != nil {
+			return err
+		}
+
+		for _, h := range hostList {
+			host, port, err := splitWildcardHostPort(h, defaultPort)
+			if err != nil {
+				return err
+			}
+
+			if isTLS, seen := tlsPorts[port]; seen && isTLS != (srv.TLS != nil) {
+				return fmt.Errorf("tls: port %d: mixed tls and plain http server configuration", port)
+			}
+			tlsPorts[port] = srv.TLS != nil
+
+			if srv.TLS == nil {
+				continue
+			}
+
+			cert, ok := hostCerts[host]
+			if !ok {
+				cert = defaultCert
+			}
+			if cert == nil {
+				return fmt.Errorf("tls: missing certificate for host %q: %q", h, srv.Name)
+			}
+
+			srvConf[port].TLSCertificates[host] = cert
+			srvConf[port].TLSRequestClientCert = requestClientCert
+		}
+	}
+
+	return nil
+}
+
+func loadServerCertificates(srv *config.Server, hostList []string, defaultPort int) (*tls.Certificate, map[string]*tls.Certificate, error) {
+	hostCerts := make(map[string]*tls.Certificate)
+	if srv.TLS == nil {
+		return nil, hostCerts, nil
+	}
+
+	var defaultCert *tls.Certificate
+	if srv.TLS.CertificateFile != "" || srv.TLS.KeyFile != "" {
+		cert, err := loadCertificate(srv.TLS.CertificateFile, srv.TLS.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		defaultCert = cert
+	}
+
+	configuredHosts := make(map[string]bool)
+	for _, h := range hostList {
+		host, _, err := splitWildcardHostPort(h, defaultPort)
+		if err != nil {
+			return nil, nil, err
+		}
+		configuredHosts[host] = true
+	}
+
+	for _, c := range srv.TLS.Certificates {
+		host := strings.ToLower(strings.TrimSpace(c.Host))
+		if !configuredHosts[host] {
+			return nil, nil, fmt.Errorf("tls: certificate host %q is not configured in hosts of server %q", c.Host, srv.Name)
+		}
+
+		if _, exist := hostCerts[host]; exist {
+			return nil, nil, fmt.Errorf("tls: duplicate certificate for host %q", c.Host)
+		}
+
+		cert, err := loadCertificate(c.CertificateFile, c.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		hostCerts[host] = cert
+	}
+
+	return defaultCert, hostCerts, nil
+}
+
+func loadCertificate(certFile, keyFile string) (*tls.Certificate, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("tls: certificate_file and key_file are required")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: loading certificate %q failed: %w", certFile, err)
+	}
+	return &cert, nil
+}
@@ -11,7 +11,9 @@ type Server struct {
 	Files                *Files    `hcl:"files,block"`
 	Hosts                []string  `hcl:"hosts,optional"`
 	Name                 string    `hcl:"name,label"`
+	RateLimit            []string  `hcl:"rate_limit,optional"`
 	Spa                  *Spa      `hcl:"spa,block"`
+	TLS                  *TLS      `hcl:"tls,block"`
 }
 
 // Servers represents a list of <Server> objects.
@@ -0,0 +1,9 @@
+package config
+
+// Throttle represents the <Throttle> object.
+type Throttle struct {
+	Burst             int     `hcl:"burst,optional"`
+	MaxQueue          int     `hcl:"max_queue,optional"`
+	MaxWait           string  `hcl:"max_wait,optional"`
+	RequestsPerSecond float64 `hcl:"requests_per_second"`
+}
@@ -0,0 +1,15 @@
+package config
+
+// TLS represents the <TLS> object.
+type TLS struct {
+	Certificates    []*Certificate `hcl:"certificate,block"`
+	CertificateFile string         `hcl:"certificate_file,optional"`
+	KeyFile         string         `hcl:"key_file,optional"`
+}
+
+// Certificate represents a host specific <Certificate> object.
+type Certificate struct {
+	CertificateFile string `hcl:"certificate_file"`
+	Host            string `hcl:"host,label"`
+	KeyFile         string `hcl:"key_file"`
+}
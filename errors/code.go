@@ -31,12 +31,24 @@ const (
 	AuthorizationRequired Code = 5000 + iota
 	AuthorizationFailed
 	BasicAuthFailed
+	InsufficientScope
+	ClientAddressDenied
+	TokenExpired
+	TokenSignatureInvalid
+	TokenIssuerInvalid
+	TokenClaimMissing
+	RateLimitExceeded
+	TokenInvalid
 )
 
 const (
 	UpstreamRequestValidationFailed Code = 6000 + iota
 	UpstreamResponseValidationFailed
 	UpstreamResponseBufferingFailed
+	UpstreamUnavailable
+	UpstreamCircuitOpen
+	UpstreamTokenRequestFailed
+	UpstreamThrottled
 )
 
 const (
@@ -68,10 +80,22 @@ var codes = map[Code]string{
 	AuthorizationRequired: "Authorization required",
 	AuthorizationFailed:   "Authorization failed",
 	BasicAuthFailed:       "Unauthorized",
+	InsufficientScope:     "Insufficient scope",
+	ClientAddressDenied:   "Client address denied",
+	TokenExpired:          "Token expired",
+	TokenSignatureInvalid: "Token signature invalid",
+	TokenIssuerInvalid:    "Token issuer invalid",
+	TokenClaimMissing:     "Token claim missing",
+	RateLimitExceeded:     "Rate limit exceeded",
+	TokenInvalid:          "Token invalid",
 	// 6xxx
 	UpstreamRequestValidationFailed:  "Upstream request validation failed",
 	UpstreamResponseValidationFailed: "Upstream response validation failed",
 	UpstreamResponseBufferingFailed:  "Upstream response buffering failed",
+	UpstreamUnavailable:              "Upstream unavailable",
+	UpstreamCircuitOpen:              "Upstream circuit breaker is open",
+	UpstreamTokenRequestFailed:       "Upstream token request failed",
+	UpstreamThrottled:                "Upstream request throttled",
 	// 7xxx
 	EndpointConnect:             "Endpoint upstream connection error",
 	EndpointProxyConnect:        "upstream connection error via configured proxy",
@@ -9,16 +9,22 @@ func httpStatus(code Code) int {
 	switch code {
 	case APIRouteNotFound, FilesRouteNotFound, RouteNotFound, SPARouteNotFound:
 		return http.StatusNotFound
-	case APIConnect, APIProxyConnect, EndpointConnect, EndpointProxyConnect, UpstreamResponseValidationFailed:
+	case APIConnect, APIProxyConnect, EndpointConnect, EndpointProxyConnect, UpstreamResponseValidationFailed,
+		UpstreamTokenRequestFailed:
 		return http.StatusBadGateway
 	case EndpointReqBodySizeExceeded:
 		return http.StatusRequestEntityTooLarge
 	case InvalidRequest, UpstreamRequestValidationFailed:
 		return http.StatusBadRequest
-	case AuthorizationRequired, BasicAuthFailed:
+	case AuthorizationRequired, BasicAuthFailed, TokenClaimMissing, TokenExpired, TokenInvalid, TokenIssuerInvalid,
+		TokenSignatureInvalid:
 		return http.StatusUnauthorized
-	case AuthorizationFailed:
+	case AuthorizationFailed, ClientAddressDenied, InsufficientScope:
 		return http.StatusForbidden
+	case RateLimitExceeded:
+		return http.StatusTooManyRequests
+	case UpstreamCircuitOpen, UpstreamThrottled, UpstreamUnavailable:
+		return http.StatusServiceUnavailable
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,57 @@
+package eval
+
+import (
+	"context"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	StateDegraded  = "degraded"
+	StateHealthy   = "healthy"
+	StateUnhealthy = "unhealthy"
+)
+
+// BackendHealth represents a backend with active health checks.
+type BackendHealth interface {
+	HealthState() HealthState
+	// Start probes the backend origins until the given context is done.
+	Start(ctx context.Context)
+}
+
+// HealthState represents the aggregated health state of all backend origins.
+type HealthState struct {
+	Healthy bool
+	State   string
+}
+
+// AddBackend registers a health checked backend by its name.
+func (c *Context) AddBackend(name string, backend BackendHealth) {
+	if c.backends == nil {
+		c.backends = make(map[string]BackendHealth)
+	}
+	c.backends[name] = backend
+}
+
+// Backends returns all registered health checked backends.
+func (c *Context) Backends() map[string]BackendHealth {
+	return c.backends
+}
+
+func newBackendsVariable(backends map[string]BackendHealth) cty.Value {
+	if len(backends) == 0 {
+		return cty.MapValEmpty(cty.NilType)
+	}
+
+	values := make(map[string]cty.Value)
+	for name, backend := range backends {
+		state := backend.HealthState()
+		values[name] = cty.ObjectVal(map[string]cty.Value{
+			Health: cty.ObjectVal(map[string]cty.Value{
+				Healthy: cty.BoolVal(state.Healthy),
+				State:   cty.StringVal(state.State),
+			}),
+		})
+	}
+	return cty.ObjectVal(values)
+}
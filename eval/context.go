@@ -3,6 +3,7 @@ package eval
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"io"
 	"io/ioutil"
@@ -22,6 +23,7 @@ import (
 	"github.com/avenga/couper/config/request"
 	"github.com/avenga/couper/eval/lib"
 	"github.com/avenga/couper/internal/seetie"
+	"github.com/avenga/couper/utils"
 )
 
 const ContextType = "evalContextType"
@@ -38,9 +40,11 @@ func (m ContextMap) Merge(other ContextMap) ContextMap {
 }
 
 type Context struct {
-	bufferOption BufferOption
-	eval         *hcl.EvalContext
-	inner        context.Context
+	backends           map[string]BackendHealth
+	bufferOption       BufferOption
+	eval               *hcl.EvalContext
+	inner              context.Context
+	jwtSigningProfiles map[string]*JWTSigningProfile
 }
 
 func NewContext(src []byte) *Context {
@@ -77,9 +81,12 @@ func (c *Context) Value(key interface{}) interface{} {
 
 func (c *Context) WithClientRequest(req *http.Request) *Context {
 	ctx := &Context{
-		bufferOption: c.bufferOption,
-		eval:         cloneContext(c.eval),
+		backends:           c.backends,
+		bufferOption:       c.bufferOption,
+		eval:               cloneContext(c.eval),
+		jwtSigningProfiles: c.jwtSigningProfiles,
 	}
+	ctx.bindFunctions()
 	ctx.inner = context.WithValue(req.Context(), ContextType, ctx)
 
 	ctxMap := ContextMap{}
@@ -105,17 +112,23 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 		PathParam: seetie.MapToValue(pathParams),
 		Post:      seetie.ValuesMapToValue(parseForm(req).PostForm),
 		Query:     seetie.ValuesMapToValue(req.URL.Query()),
+		TLS:       seetie.MapToValue(newTLSVariable(req.TLS)),
 		URL:       cty.StringVal(newRawURL(req.URL).String()),
 	}.Merge(newVariable(ctx.inner, req.Cookies(), req.Header))))
 
+	ctx.eval.Variables[Backends] = newBackendsVariable(ctx.backends)
+
 	return ctx
 }
 
 func (c *Context) WithBeresps(beresps ...*http.Response) *Context {
 	ctx := &Context{
-		bufferOption: c.bufferOption,
-		eval:         cloneContext(c.eval),
+		backends:           c.backends,
+		bufferOption:       c.bufferOption,
+		eval:               cloneContext(c.eval),
+		jwtSigningProfiles: c.jwtSigningProfiles,
 	}
+	ctx.bindFunctions()
 	ctx.inner = context.WithValue(c.inner, ContextType, ctx)
 
 	resps := make(ContextMap, 0)
@@ -156,6 +169,13 @@ func (c *Context) WithBeresps(beresps ...*http.Response) *Context {
 	return ctx
 }
 
+// bindFunctions replaces the cloned functions which depend on the evaluation context.
+func (c *Context) bindFunctions() {
+	if len(c.jwtSigningProfiles) > 0 {
+		c.eval.Functions[FnJWTSign] = newJWTSignFunction(c.jwtSigningProfiles, c.eval)
+	}
+}
+
 func (c Context) HCLContext() *hcl.EvalContext {
 	return c.eval
 }
@@ -270,6 +290,20 @@ func newVariable(ctx context.Context, cookies []*http.Cookie, headers http.Heade
 	}
 }
 
+// newTLSVariable provides the connection details of a TLS terminated client request.
+func newTLSVariable(state *tls.ConnectionState) map[string]interface{} {
+	if state == nil {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"cipher_suite":        tls.CipherSuiteName(state.CipherSuite),
+		"negotiated_protocol": state.NegotiatedProtocol,
+		"server_name":         state.ServerName,
+		"version":             utils.TLSVersionName(state.Version),
+	}
+}
+
 func newCtyEnvMap(envKeys []string) cty.Value {
 	if len(envKeys) == 0 {
 		return cty.MapValEmpty(cty.String)
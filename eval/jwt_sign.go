@@ -0,0 +1,79 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/avenga/couper/internal/seetie"
+)
+
+const FnJWTSign = "jwt_sign"
+
+// JWTSigner creates signed tokens with the given claims.
+type JWTSigner interface {
+	Sign(claims map[string]interface{}) (string, error)
+}
+
+// JWTSigningProfile represents a signer with its configured claims
+// which may refer to request variables.
+type JWTSigningProfile struct {
+	Claims hcl.Expression
+	Signer JWTSigner
+}
+
+// AddJWTSigningProfile registers a profile by its name for the jwt_sign() function.
+func (c *Context) AddJWTSigningProfile(name string, profile *JWTSigningProfile) {
+	if c.jwtSigningProfiles == nil {
+		c.jwtSigningProfiles = make(map[string]*JWTSigningProfile)
+	}
+	c.jwtSigningProfiles[name] = profile
+	c.eval.Functions[FnJWTSign] = newJWTSignFunction(c.jwtSigningProfiles, c.eval)
+}
+
+// newJWTSignFunction creates the jwt_sign() function bound to the given context
+// which is required to evaluate the profile claims with the current request variables.
+func newJWTSignFunction(profiles map[string]*JWTSigningProfile, ctx *hcl.EvalContext) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "jwt_signing_profile_label",
+				Type: cty.String,
+			},
+			{
+				Name:      "claims",
+				Type:      cty.DynamicPseudoType,
+				AllowNull: true,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			label := args[0].AsString()
+			profile, exist := profiles[label]
+			if !exist {
+				return cty.StringVal(""), fmt.Errorf("missing jwt_signing_profile definition for %q", label)
+			}
+
+			claims := make(map[string]interface{})
+			if profile.Claims != nil {
+				v, diags := profile.Claims.Value(ctx)
+				if seetie.SetSeverityLevel(diags).HasErrors() {
+					return cty.StringVal(""), diags
+				}
+				claims = seetie.ValueToMap(v)
+			}
+
+			for k, v := range seetie.ValueToMap(args[1]) {
+				claims[k] = v
+			}
+
+			token, err := profile.Signer.Sign(claims)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+			return cty.StringVal(token), nil
+		},
+	})
+}
@@ -6,12 +6,15 @@ const (
 	BackendRequests  = "bereqs"
 	BackendResponses = "beresps"
 	BackendDefault   = "default"
+	Backends         = "backends"
 	ClientRequest    = "req"
 	CTX              = "ctx"
 	Cookies          = "cookies"
 	Endpoint         = "endpoint"
 	Environment      = "env"
 	Headers          = "headers"
+	Health           = "health"
+	Healthy          = "healthy"
 	HttpStatus       = "status"
 	ID               = "id"
 	JsonBody         = "json_body"
@@ -20,5 +23,7 @@ const (
 	PathParam        = "path_params"
 	Post             = "post"
 	Query            = "query"
+	State            = "state"
+	TLS              = "tls"
 	URL              = "url"
 )
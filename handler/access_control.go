@@ -1,25 +1,33 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	ac "github.com/avenga/couper/accesscontrol"
-	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/config/request"
+	couperErr "github.com/avenga/couper/errors"
 )
 
 var (
-	_ http.Handler         = &AccessControl{}
-	_ errors.ErrorTemplate = &AccessControl{}
-	_ ac.ProtectedHandler  = &AccessControl{}
+	_ http.Handler            = &AccessControl{}
+	_ couperErr.ErrorTemplate = &AccessControl{}
+	_ ac.ProtectedHandler     = &AccessControl{}
 )
 
 type AccessControl struct {
 	ac        ac.List
-	errorTpl  *errors.Template
+	errorTpl  *couperErr.Template
 	protected http.Handler
 }
 
-func NewAccessControl(protected http.Handler, errTpl *errors.Template, list ...ac.AccessControl) *AccessControl {
+func NewAccessControl(protected http.Handler, errTpl *couperErr.Template, list ...ac.AccessControl) *AccessControl {
 	return &AccessControl{
 		ac:        list,
 		errorTpl:  errTpl,
@@ -30,36 +38,100 @@ func NewAccessControl(protected http.Handler, errTpl *errors.Template, list ...a
 func (a *AccessControl) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	for _, control := range a.ac {
 		if err := control.Validate(req); err != nil {
-			var code errors.Code
-			if authError, ok := err.(*ac.BasicAuthError); ok {
-				code = errors.BasicAuthFailed
-				wwwAuthenticateValue := "Basic"
-				if authError.Realm != "" {
-					wwwAuthenticateValue += " realm=" + authError.Realm
-				}
-				rw.Header().Set("WWW-Authenticate", wwwAuthenticateValue)
-			} else {
-				switch err {
-				case ac.ErrorNotConfigured:
-					code = errors.Configuration
-				case ac.ErrorEmptyToken:
-					code = errors.AuthorizationRequired
-				default:
-					code = errors.AuthorizationFailed
+			// the validation error is logged within the access log
+			*req = *req.WithContext(context.WithValue(req.Context(), request.AccessControlError, err))
+
+			var redirect *ac.RedirectError
+			if errors.As(err, &redirect) {
+				for _, cookie := range redirect.Cookies {
+					http.SetCookie(rw, cookie)
 				}
+				http.Redirect(rw, req, redirect.Location, http.StatusSeeOther)
+				return
 			}
-			a.errorTpl.ServeError(code).ServeHTTP(rw, req)
+
+			errorTpl := a.errorTpl
+			var namedErr *ac.NamedError
+			if errors.As(err, &namedErr) && namedErr.Template != nil {
+				errorTpl = namedErr.Template
+			}
+
+			errorTpl.ServeError(errorCode(rw, err)).ServeHTTP(rw, req)
 			return
 		}
 	}
 	a.protected.ServeHTTP(rw, req)
 }
 
+// errorCode returns the error code of the given access control error and sets
+// the related WWW-Authenticate challenge.
+func errorCode(rw http.ResponseWriter, err error) couperErr.Code {
+	var (
+		basicAuthErr *ac.BasicAuthError
+		bearerErr    *ac.BearerError
+		rateLimitErr *ac.RateLimitError
+		scopeErr     *ac.ScopeError
+	)
+
+	switch {
+	case errors.As(err, &basicAuthErr):
+		wwwAuthenticateValue := "Basic"
+		if basicAuthErr.Realm != "" {
+			wwwAuthenticateValue += " realm=" + basicAuthErr.Realm
+		}
+		rw.Header().Set("WWW-Authenticate", wwwAuthenticateValue)
+		return couperErr.BasicAuthFailed
+	case errors.As(err, &scopeErr):
+		rw.Header().Set("WWW-Authenticate",
+			fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, strings.Join(scopeErr.Missing, " ")))
+		return couperErr.InsufficientScope
+	case errors.As(err, &bearerErr):
+		// RFC 6750 section 3.1: invalid_request is responded with 400, invalid_token with 401
+		rw.Header().Set("WWW-Authenticate", bearerErr.Challenge())
+		if code, ok := ac.TokenErrorCode(err); ok {
+			return code
+		}
+
+		switch {
+		case errors.Is(err, ac.ErrorNotConfigured):
+			return couperErr.Configuration
+		case errors.Is(err, ac.ErrorEmptyToken):
+			return couperErr.AuthorizationRequired
+		case errors.Is(err, ac.ErrorBearerRequired):
+			return couperErr.InvalidRequest
+		default:
+			return couperErr.TokenInvalid
+		}
+	case errors.As(err, &rateLimitErr):
+		rw.Header().Set("Retry-After", seconds(rateLimitErr.RetryAfter))
+		rw.Header().Set("RateLimit-Limit", strconv.Itoa(rateLimitErr.Limit))
+		rw.Header().Set("RateLimit-Remaining", "0")
+		rw.Header().Set("RateLimit-Reset", seconds(rateLimitErr.Reset))
+		return couperErr.RateLimitExceeded
+	}
+
+	if code, ok := ac.TokenErrorCode(err); ok {
+		return code
+	}
+
+	switch {
+	case errors.Is(err, ac.ErrorIPDenied):
+		return couperErr.ClientAddressDenied
+	case errors.Is(err, ac.ErrorNotConfigured), errors.Is(err, ac.ErrorClientCertificateNotConfigured):
+		return couperErr.Configuration
+	case errors.Is(err, ac.ErrorEmptyToken), errors.Is(err, ac.ErrorClientCertificateMissing),
+		errors.Is(err, ac.ErrorOIDCSessionRequired):
+		return couperErr.AuthorizationRequired
+	default:
+		return couperErr.AuthorizationFailed
+	}
+}
+
 func (a *AccessControl) Child() http.Handler {
 	return a.protected
 }
 
-func (a *AccessControl) Template() *errors.Template {
+func (a *AccessControl) Template() *couperErr.Template {
 	return a.errorTpl
 }
 
@@ -69,3 +141,8 @@ func (a *AccessControl) String() string {
 	}
 	return "AccessControl"
 }
+
+// seconds returns the given duration as delay-seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/eval"
 )
 
 const healthPath = "/healthz"
@@ -14,6 +15,7 @@ var _ http.Handler = &Health{}
 type Health struct {
 	path       string
 	shutdownCh chan struct{}
+	backends   map[string]eval.BackendHealth
 }
 
 func NewHealthCheck(path string, shutdownCh chan struct{}) *Health {
@@ -32,6 +34,14 @@ func NewHealthCheck(path string, shutdownCh chan struct{}) *Health {
 	}
 }
 
+// WithBackends returns a copy of the health check which reports the
+// degraded or unhealthy state of the given health checked backends.
+func (h *Health) WithBackends(backends map[string]eval.BackendHealth) *Health {
+	health := *h
+	health.backends = backends
+	return &health
+}
+
 func (h *Health) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
 	rw.Header().Set("Cache-Control", "no-store")
 	rw.Header().Set("Content-Type", "text/plain")
@@ -42,8 +52,31 @@ func (h *Health) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
 		rw.WriteHeader(http.StatusInternalServerError)
 		_, _ = rw.Write([]byte("server shutting down"))
 	default:
-		_, _ = rw.Write([]byte("healthy"))
+		switch h.backendsState() {
+		case eval.StateUnhealthy:
+			errors.SetHeader(rw, errors.UpstreamUnavailable)
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = rw.Write([]byte(eval.StateUnhealthy))
+		case eval.StateDegraded:
+			_, _ = rw.Write([]byte(eval.StateDegraded))
+		default:
+			_, _ = rw.Write([]byte(eval.StateHealthy))
+		}
+	}
+}
+
+// backendsState returns the worst state of all backends.
+func (h *Health) backendsState() string {
+	state := eval.StateHealthy
+	for _, backend := range h.backends {
+		switch backend.HealthState().State {
+		case eval.StateUnhealthy:
+			return eval.StateUnhealthy
+		case eval.StateDegraded:
+			state = eval.StateDegraded
+		}
 	}
+	return state
 }
 
 func (h *Health) Match(req *http.Request) bool {
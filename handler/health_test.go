@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+
+	"github.com/avenga/couper/eval"
 )
 
 func TestHealth_Match(t *testing.T) {
@@ -109,10 +112,10 @@ func TestNewHealthCheck(t *testing.T) {
 		args args
 		want *Health
 	}{
-		{"/w given path", args{"/myhealth", shutdownChan}, &Health{"/myhealth", shutdownChan}},
-		{"/w given path w/o leading slash", args{"myhealth", shutdownChan}, &Health{"/myhealth", shutdownChan}},
-		{"w/o given path", args{"", shutdownChan}, &Health{healthPath, shutdownChan}},
-		{"w/o given path & chan", args{"", nil}, &Health{healthPath, nil}},
+		{"/w given path", args{"/myhealth", shutdownChan}, &Health{"/myhealth", shutdownChan, nil}},
+		{"/w given path w/o leading slash", args{"myhealth", shutdownChan}, &Health{"/myhealth", shutdownChan, nil}},
+		{"w/o given path", args{"", shutdownChan}, &Health{healthPath, shutdownChan, nil}},
+		{"w/o given path & chan", args{"", nil}, &Health{healthPath, nil, nil}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -122,3 +125,48 @@ func TestNewHealthCheck(t *testing.T) {
 		})
 	}
 }
+
+type testBackendHealth string
+
+func (t testBackendHealth) HealthState() eval.HealthState {
+	return eval.HealthState{Healthy: string(t) != eval.StateUnhealthy, State: string(t)}
+}
+
+func (t testBackendHealth) Start(_ context.Context) {}
+
+func TestHealth_ServeHTTP_Backends(t *testing.T) {
+	tests := []struct {
+		name       string
+		backends   map[string]eval.BackendHealth
+		wantStatus int
+		wantBody   string
+	}{
+		{"w/o backends", nil, http.StatusOK, eval.StateHealthy},
+		{"healthy backends", map[string]eval.BackendHealth{"a": testBackendHealth(eval.StateHealthy)}, http.StatusOK, eval.StateHealthy},
+		{"degraded backend", map[string]eval.BackendHealth{
+			"a": testBackendHealth(eval.StateHealthy),
+			"b": testBackendHealth(eval.StateDegraded),
+		}, http.StatusOK, eval.StateDegraded},
+		{"unhealthy backend", map[string]eval.BackendHealth{
+			"a": testBackendHealth(eval.StateDegraded),
+			"b": testBackendHealth(eval.StateUnhealthy),
+		}, http.StatusServiceUnavailable, eval.StateUnhealthy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthCheck("", make(chan struct{})).WithBackends(tt.backends)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			res := rec.Result()
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("Expected statusCode: %d, got: %d", tt.wantStatus, res.StatusCode)
+			}
+
+			body, _ := ioutil.ReadAll(res.Body)
+			if string(body) != tt.wantBody {
+				t.Errorf("Expected %q body content, got %q", tt.wantBody, string(body))
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 type Proxy struct {
 	Name      string // label
+	Retry     *Retry
 	RoundTrip http.RoundTripper
 }
 
@@ -27,6 +28,6 @@ func (pr Proxies) Produce(ctx context.Context, clientReq *http.Request, results
 		outCtx := withRoundTripName(ctx, proxy.Name)
 		outCtx = context.WithValue(outCtx, request.RoundTripProxy, true)
 		outReq := clientReq.WithContext(outCtx)
-		go roundtrip(proxy.RoundTrip, outReq, results, wg)
+		go roundtrip(proxy.RoundTrip, proxy.Retry, outReq, results, wg)
 	}
 }
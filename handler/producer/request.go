@@ -20,6 +20,7 @@ type Request struct {
 	// Dispatch bool
 	Method string
 	Name   string // label
+	Retry  *Retry
 	URL    string
 }
 
@@ -50,7 +51,7 @@ func (r Requests) Produce(ctx context.Context, req *http.Request, results chan<-
 			continue
 		}
 		*outreq = *outreq.WithContext(outCtx)
-		go roundtrip(or.Backend, outreq, results, wg)
+		go roundtrip(or.Backend, or.Retry, outreq, results, wg)
 	}
 }
 
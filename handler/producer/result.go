@@ -25,10 +25,10 @@ func (rm ResultMap) List() []*http.Response {
 	return list
 }
 
-func roundtrip(rt http.RoundTripper, req *http.Request, results chan<- *Result, wg *sync.WaitGroup) {
+func roundtrip(rt http.RoundTripper, retry *Retry, req *http.Request, results chan<- *Result, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	// TODO: apply evals here with context?
-	beresp, err := rt.RoundTrip(req)
+	beresp, err := retry.RoundTrip(rt, req)
 	results <- &Result{Beresp: beresp, Err: err}
 }
@@ -0,0 +1,108 @@
+package producer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/avenga/couper/config/request"
+	couperErr "github.com/avenga/couper/errors"
+)
+
+// IdempotentMethods are the default methods to retry, see RFC 7231, section 4.2.2.
+var IdempotentMethods = []string{
+	http.MethodDelete,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPut,
+	http.MethodTrace,
+}
+
+// Retry represents the producer <Retry> object.
+type Retry struct {
+	Attempts      int
+	Backoff       time.Duration
+	MaxBackoff    time.Duration
+	Methods       map[string]bool
+	NetworkErrors bool
+	StatusCodes   map[int]bool
+}
+
+// RoundTrip calls the given <http.RoundTripper> until the response is not retryable
+// or all attempts are exhausted. A nil <*Retry> results in a single attempt.
+func (r *Retry) RoundTrip(rt http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if r == nil || r.Attempts < 2 || !r.Methods[req.Method] {
+		return rt.RoundTrip(req)
+	}
+
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		outreq := withAttempt(req, attempt)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			outreq.Body = body
+		}
+
+		beresp, err := rt.RoundTrip(outreq)
+		if attempt >= r.Attempts || !replayable || !r.retryable(req.Context(), beresp, err) {
+			return beresp, err
+		}
+
+		if beresp != nil && beresp.Body != nil {
+			_, _ = io.Copy(ioutil.Discard, beresp.Body)
+			_ = beresp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(r.backoff(attempt)):
+		}
+	}
+}
+
+func (r *Retry) retryable(ctx context.Context, beresp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		if _, ok := err.(couperErr.Code); ok {
+			return false
+		}
+		return r.NetworkErrors && !errors.Is(err, context.Canceled)
+	}
+
+	return beresp != nil && r.StatusCodes[beresp.StatusCode]
+}
+
+// backoff returns the exponential delay for the given attempt with a random jitter of up to the half.
+func (r *Retry) backoff(attempt int) time.Duration {
+	delay := r.Backoff
+	for i := 1; i < attempt && delay < r.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if r.MaxBackoff > 0 && delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int63n(half+1))
+	}
+	return delay
+}
+
+// withAttempt clones the request with a fresh header map and url for each attempt
+// since the roundtrip applies its modifications to the given request.
+func withAttempt(req *http.Request, attempt int) *http.Request {
+	return req.Clone(context.WithValue(req.Context(), request.RoundTripAttempt, attempt))
+}
@@ -0,0 +1,107 @@
+package producer_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/avenga/couper/handler/producer"
+	"github.com/avenga/couper/handler/transport"
+	"github.com/avenga/couper/internal/test"
+	"github.com/avenga/couper/logging"
+)
+
+func TestRetry_RoundTrip(t *testing.T) {
+	var requests int32
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		body, _ := ioutil.ReadAll(req.Body)
+		if string(body) != req.Header.Get("X-Body") {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if n < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer origin.Close()
+
+	retry := &producer.Retry{
+		Attempts:    3,
+		Backoff:     time.Millisecond * 10,
+		MaxBackoff:  time.Millisecond * 20,
+		Methods:     map[string]bool{http.MethodGet: true, http.MethodPut: true},
+		StatusCodes: map[int]bool{http.StatusServiceUnavailable: true},
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		retry        *producer.Retry
+		wantStatus   int
+		wantAttempts int32
+	}{
+		{"w/o retry", http.MethodGet, "", nil, http.StatusServiceUnavailable, 1},
+		{"retry GET", http.MethodGet, "", retry, http.StatusNoContent, 3},
+		{"retry PUT with body", http.MethodPut, "payload", retry, http.StatusNoContent, 3},
+		{"non idempotent POST", http.MethodPost, "payload", retry, http.StatusServiceUnavailable, 1},
+	}
+
+	logger, hook := logrustest.NewNullLogger()
+	log := logger.WithContext(context.Background())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			hook.Reset()
+
+			backend := transport.NewBackend(test.NewRemainContext("origin", origin.URL), &transport.Config{}, nil, log)
+
+			req := httptest.NewRequest(tt.method, "http://couper.io/", strings.NewReader(tt.body))
+			req.Header.Set("X-Body", tt.body)
+			req.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(strings.NewReader(tt.body)), nil
+			}
+
+			res, err := tt.retry.RoundTrip(backend, req)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			if res.StatusCode != tt.wantStatus {
+				subT.Errorf("expected status %d, got: %d", tt.wantStatus, res.StatusCode)
+			}
+
+			if n := atomic.LoadInt32(&requests); n != tt.wantAttempts {
+				subT.Errorf("expected %d attempts, got: %d", tt.wantAttempts, n)
+			}
+
+			entries := hook.AllEntries()
+			if int32(len(entries)) != tt.wantAttempts {
+				subT.Fatalf("expected %d log entries, got: %d", tt.wantAttempts, len(entries))
+			}
+
+			if tt.retry == nil || tt.wantAttempts == 1 {
+				return
+			}
+
+			for i, entry := range entries {
+				attempt := entry.Data["request"].(logging.Fields)["attempt"]
+				if attempt != i+1 {
+					subT.Errorf("expected attempt %d, got: %v", i+1, attempt)
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -19,6 +22,7 @@ import (
 	"github.com/avenga/couper/handler/validation"
 	"github.com/avenga/couper/internal/seetie"
 	"github.com/avenga/couper/logging"
+	"github.com/avenga/couper/utils"
 )
 
 const (
@@ -35,14 +39,14 @@ var ReClientSupportsGZ = regexp.MustCompile(`(?i)\b` + GzipName + `\b`)
 
 // Backend represents the transport configuration.
 type Backend struct {
+	circuitBreaker   *CircuitBreaker
 	context          hcl.Body
 	name             string
 	openAPIValidator *validation.OpenAPI
 	options          *BackendOptions
+	throttle         *Throttle
 	transportConf    *Config
 	upstreamLog      *logging.UpstreamLog
-	// oauth
-	// ...
 	// TODO: OrderedList for origin AC, middlewares etc.
 }
 
@@ -65,14 +69,82 @@ func NewBackend(ctx hcl.Body, tc *Config, opts *BackendOptions, log *logrus.Entr
 		transportConf:    tc,
 	}
 	backend.upstreamLog = logging.NewUpstreamLog(logEntry, backend, tc.NoProxyFromEnv)
+
+	if opts != nil {
+		backend.circuitBreaker = opts.CircuitBreaker
+		backend.throttle = opts.Throttle
+	}
+
 	return backend.upstreamLog
 }
 
 // RoundTrip implements the <http.RoundTripper> interface.
 func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
-	tc := b.evalTransport(req)
+	if b.circuitBreaker == nil {
+		if err := b.wait(req); err != nil {
+			return nil, err
+		}
+		return b.roundTrip(req)
+	}
+
+	// an open circuit fails fast instead of occupying the throttle queue
+	done, cancel, err := b.circuitBreaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.wait(req); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	beresp, err := b.roundTrip(req)
+	done(isOriginFailure(beresp, err))
+	return beresp, err
+}
+
+// wait blocks while the backend requests are throttled. The queue position and
+// waiting time are logged with the upstream timings.
+func (b *Backend) wait(req *http.Request) error {
+	if b.throttle == nil {
+		return nil
+	}
+
+	depth, wait, err := b.throttle.Wait(req.Context())
+	logging.SetUpstreamTiming(req.Context(), "throttle_queue", depth)
+	logging.SetUpstreamTiming(req.Context(), "throttle_wait", wait)
+	return err
+}
+
+// isOriginFailure reports connection errors, timeouts and server error responses.
+// Couper related errors like validation failures are not caused by the origin.
+func isOriginFailure(beresp *http.Response, err error) bool {
+	if err != nil {
+		if _, ok := err.(couperErr.Code); ok {
+			return false
+		}
+		return !errors.Is(err, context.Canceled)
+	}
+	return beresp.StatusCode >= http.StatusInternalServerError
+}
+
+func (b *Backend) roundTrip(req *http.Request) (*http.Response, error) {
+	tc, origin, err := b.evalTransport(req)
+	if err != nil {
+		return nil, err
+	}
 	t := Get(tc)
 
+	var release func()
+	if origin != nil {
+		release = origin.acquire()
+		defer func() {
+			if release != nil { // not handed over to the response body
+				release()
+			}
+		}()
+	}
+
 	if b.transportConf.Timeout > 0 {
 		deadline, cancel := context.WithTimeout(req.Context(), b.transportConf.Timeout)
 		defer cancel()
@@ -86,12 +158,28 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.URL.Host = tc.Origin
 	req.Host = tc.Hostname
 
-	err := eval.ApplyRequestContext(req.Context(), b.context, req)
+	err = eval.ApplyRequestContext(req.Context(), b.context, req)
 	if err != nil {
 		return nil, err
 	}
 
-	// oauth ....
+	// The prefix applies to the already evaluated endpoint, proxy and backend path.
+	if pathPrefix := b.evalAttribute(req, "path_prefix"); pathPrefix != "" {
+		// keep encoded path segments like %2F, a stale RawPath would be ignored
+		if req.URL.RawPath != "" {
+			req.URL.RawPath = utils.JoinPath("/", (&url.URL{Path: pathPrefix}).EscapedPath(), req.URL.RawPath)
+		}
+		req.URL.Path = utils.JoinPath("/", pathPrefix, req.URL.Path)
+	}
+
+	var token string
+	if b.options != nil && b.options.OAuth2 != nil {
+		if token, err = b.options.OAuth2.Token(req.Context()); err != nil {
+			b.upstreamLog.LogEntry().WithError(err).Error("oauth2 token request failed")
+			return nil, couperErr.UpstreamTokenRequestFailed
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 
 	if b.options != nil && b.options.BasicAuth != "" {
 		auth := base64.StdEncoding.EncodeToString([]byte(b.options.BasicAuth))
@@ -121,6 +209,9 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 	setUserAgent(req)
 	req.Close = false
 	beresp, err := t.RoundTrip(req)
+	if err == nil && token != "" && beresp.StatusCode == http.StatusUnauthorized {
+		beresp, err = b.renewToken(t, req, token, beresp)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -153,10 +244,45 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 		err = eval.ApplyResponseContext(req.Context(), b.context, beresp)
 	}
 
+	if release != nil {
+		// the origin is in use until the response body has been read
+		beresp.Body = &releaseReadCloser{ReadCloser: beresp.Body, release: release}
+		release = nil
+	}
+
 	return beresp, err
 }
 
-func (b *Backend) evalTransport(req *http.Request) *Config {
+// renewToken repeats the request once with a new access token since the
+// origin has rejected the given one, e.g. due to an early revocation.
+func (b *Backend) renewToken(t http.RoundTripper, req *http.Request, token string, beresp *http.Response) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil { // not replayable
+		return beresp, nil
+	}
+
+	b.options.OAuth2.Invalidate(token)
+	newToken, err := b.options.OAuth2.Token(req.Context())
+	if err != nil {
+		b.upstreamLog.LogEntry().WithError(err).Error("oauth2 token request failed")
+		return beresp, nil
+	}
+
+	_, _ = io.Copy(ioutil.Discard, beresp.Body)
+	_ = beresp.Body.Close()
+
+	if req.GetBody != nil {
+		if req.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+newToken)
+
+	return t.RoundTrip(req)
+}
+
+// evalTransport returns the transport configuration for the given request
+// and the selected origin in case of a load balanced backend.
+func (b *Backend) evalTransport(req *http.Request) (*Config, *Origin, error) {
 	var httpContext *hcl.EvalContext
 	if httpCtx, ok := req.Context().Value(eval.ContextType).(*eval.Context); ok {
 		httpContext = httpCtx.HCLContext()
@@ -175,12 +301,50 @@ func (b *Backend) evalTransport(req *http.Request) *Config {
 		hostname = h
 	}
 
-	originURL, _ := url.Parse(origin)
+	var selected *Origin
+	var originURL *url.URL
+	if b.options != nil && b.options.Balancer != nil {
+		selected = b.options.Balancer.Next(httpContext)
+		if selected == nil {
+			return nil, nil, couperErr.UpstreamUnavailable
+		}
+		originURL = selected.URL
+	} else {
+		originURL, _ = url.Parse(origin)
+	}
+
 	if hostname == "" {
 		hostname = originURL.Host
 	}
 
-	return b.transportConf.With(originURL.Scheme, originURL.Host, hostname)
+	return b.transportConf.With(originURL.Scheme, originURL.Host, hostname), selected, nil
+}
+
+// evalAttribute evaluates the given inline backend attribute with the request context.
+func (b *Backend) evalAttribute(req *http.Request, name string) string {
+	var httpContext *hcl.EvalContext
+	if httpCtx, ok := req.Context().Value(eval.ContextType).(*eval.Context); ok {
+		httpContext = httpCtx.HCLContext()
+	}
+
+	content, _, diags := b.context.PartialContent(config.BackendInlineSchema)
+	if diags.HasErrors() {
+		b.upstreamLog.LogEntry().Error(diags)
+		return ""
+	}
+
+	return getAttribute(httpContext, name, content)
+}
+
+// releaseReadCloser calls the release function on close.
+type releaseReadCloser struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseReadCloser) Close() error {
+	r.release()
+	return r.ReadCloser.Close()
 }
 
 func getAttribute(ctx *hcl.EvalContext, name string, body *hcl.BodyContent) string {
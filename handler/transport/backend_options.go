@@ -4,7 +4,10 @@ import "github.com/avenga/couper/handler/validation"
 
 // BackendOptions represents the transport <BackendOptions> object.
 type BackendOptions struct {
-	BasicAuth  string
-	OpenAPI    *validation.OpenAPIOptions
-	PathPrefix string
+	Balancer       *Balancer
+	BasicAuth      string
+	CircuitBreaker *CircuitBreaker
+	OAuth2         *OAuth2
+	OpenAPI        *validation.OpenAPIOptions
+	Throttle       *Throttle
 }
@@ -307,6 +307,24 @@ func TestBackend_director(t *testing.T) {
 			hostname =  "couper.io"
 			path = "/docs/**"
 		`, "/", context.WithValue(bgCtx, request.Wildcard, ""), httptest.NewRequest("GET", "http://couper.io/docs/", nil)},
+		{"proxy url settings w/path_prefix", `
+			origin = "http://1.2.3.4"
+			path_prefix = "/api/v1"
+		`, "/users", bgCtx, httptest.NewRequest("GET", "http://1.2.3.4/api/v1/users", nil)},
+		{"proxy url settings w/path_prefix & path", `
+			origin = "http://1.2.3.4"
+			path_prefix = "/api/v1/"
+			path = "/accounts/"
+		`, "/users", bgCtx, httptest.NewRequest("GET", "http://1.2.3.4/api/v1/accounts/", nil)},
+		{"proxy url settings w/path_prefix & wildcard ctx", `
+			origin = "http://1.2.3.4"
+			path_prefix = "/${"api"}/v1"
+			path = "/docs/**"
+		`, "/peter", context.WithValue(bgCtx, request.Wildcard, "hans"), httptest.NewRequest("GET", "http://1.2.3.4/api/v1/docs/hans", nil)},
+		{"proxy url settings w/path_prefix & encoded slash", `
+			origin = "http://1.2.3.4"
+			path_prefix = "/api/v1"
+		`, "/users/a%2Fb", bgCtx, httptest.NewRequest("GET", "http://1.2.3.4/api/v1/users/a%2Fb", nil)},
 	}
 
 	for _, tt := range tests {
@@ -338,6 +356,10 @@ func TestBackend_director(t *testing.T) {
 			if req.URL.Path != tt.expReq.URL.Path {
 				t.Errorf("expected path: %q, got: %q", tt.expReq.URL.Path, req.URL.Path)
 			}
+
+			if req.URL.EscapedPath() != tt.expReq.URL.EscapedPath() {
+				t.Errorf("expected escaped path: %q, got: %q", tt.expReq.URL.EscapedPath(), req.URL.EscapedPath())
+			}
 		})
 	}
 }
@@ -0,0 +1,261 @@
+package transport
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/avenga/couper/internal/seetie"
+)
+
+const (
+	StrategyConsistentHash   = "consistent_hash"
+	StrategyLeastConnections = "least_connections"
+	StrategyRandom           = "random"
+	StrategyRoundRobin       = "round_robin"
+)
+
+// ringReplicas defines the amount of hash ring entries per origin weight.
+const ringReplicas = 64
+
+// Origin represents a weighted upstream of a load balanced backend.
+type Origin struct {
+	URL    *url.URL
+	Weight int
+
+	connections int64
+	unhealthy   int32
+}
+
+// NewOrigin parses the given origin url. A weight less than one results in the default weight of one.
+func NewOrigin(origin string, weight int) (*Origin, error) {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid origin: %q", origin)
+	}
+	if weight < 1 {
+		weight = 1
+	}
+	return &Origin{URL: u, Weight: weight}, nil
+}
+
+// Connections returns the amount of currently running requests.
+func (o *Origin) Connections() int64 {
+	return atomic.LoadInt64(&o.connections)
+}
+
+// IsHealthy returns the result of the latest health checks.
+// Origins without health checks are always healthy.
+func (o *Origin) IsHealthy() bool {
+	return atomic.LoadInt32(&o.unhealthy) == 0
+}
+
+func (o *Origin) setHealthy(healthy bool) {
+	var v int32
+	if !healthy {
+		v = 1
+	}
+	atomic.StoreInt32(&o.unhealthy, v)
+}
+
+// acquire marks a running request. The returned release function must be called once the request has finished.
+func (o *Origin) acquire() func() {
+	atomic.AddInt64(&o.connections, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.AddInt64(&o.connections, -1)
+		})
+	}
+}
+
+type ringEntry struct {
+	hash   uint32
+	origin *Origin
+}
+
+// Balancer selects an origin per request based on the configured strategy.
+type Balancer struct {
+	hashKey  hcl.Expression
+	mu       sync.Mutex
+	origins  []*Origin
+	ring     []ringEntry
+	strategy string
+	// smooth weighted round robin state per origin
+	current []int
+}
+
+// NewBalancer creates a new <*Balancer> object. An empty strategy defaults to round robin.
+func NewBalancer(strategy string, hashKey hcl.Expression, origins []*Origin) (*Balancer, error) {
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("load_balancer: missing upstream definition")
+	}
+
+	if strategy == "" {
+		strategy = StrategyRoundRobin
+	}
+
+	b := &Balancer{
+		hashKey:  hashKey,
+		origins:  origins,
+		strategy: strategy,
+		current:  make([]int, len(origins)),
+	}
+
+	switch strategy {
+	case StrategyConsistentHash:
+		if hashKey == nil {
+			return nil, fmt.Errorf("load_balancer: strategy %q requires a hash_key", strategy)
+		}
+		b.initRing()
+	case StrategyLeastConnections, StrategyRandom, StrategyRoundRobin:
+	default:
+		return nil, fmt.Errorf("load_balancer: unsupported strategy: %q", strategy)
+	}
+
+	return b, nil
+}
+
+// Origins returns the configured origins.
+func (b *Balancer) Origins() []*Origin {
+	return b.origins
+}
+
+// Next returns the origin for the given request context.
+// Unhealthy origins are skipped, the result is nil if no origin is healthy.
+func (b *Balancer) Next(ctx *hcl.EvalContext) *Origin {
+	switch b.strategy {
+	case StrategyConsistentHash:
+		if key := b.evalHashKey(ctx); key != "" {
+			return b.lookup(key)
+		}
+		// no key, no affinity
+		return b.nextRoundRobin()
+	case StrategyLeastConnections:
+		return b.nextLeastConnections()
+	case StrategyRandom:
+		return b.nextRandom()
+	default:
+		return b.nextRoundRobin()
+	}
+}
+
+// nextRoundRobin implements the smooth weighted round robin selection.
+func (b *Balancer) nextRoundRobin() *Origin {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	selected, total := -1, 0
+	for i, o := range b.origins {
+		if !o.IsHealthy() {
+			continue
+		}
+		total += o.Weight
+		b.current[i] += o.Weight
+		if selected < 0 || b.current[i] > b.current[selected] {
+			selected = i
+		}
+	}
+
+	if selected < 0 {
+		return nil
+	}
+	b.current[selected] -= total
+	return b.origins[selected]
+}
+
+// nextLeastConnections selects the origin with the least running requests in relation to its weight.
+func (b *Balancer) nextLeastConnections() *Origin {
+	var selected *Origin
+	var selectedLoad float64
+	for _, o := range b.origins {
+		if !o.IsHealthy() {
+			continue
+		}
+		load := float64(o.Connections()) / float64(o.Weight)
+		if selected == nil || load < selectedLoad {
+			selected = o
+			selectedLoad = load
+		}
+	}
+	return selected
+}
+
+func (b *Balancer) nextRandom() *Origin {
+	var total int
+	for _, o := range b.origins {
+		if o.IsHealthy() {
+			total += o.Weight
+		}
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	n := rand.Intn(total)
+	for _, o := range b.origins {
+		if !o.IsHealthy() {
+			continue
+		}
+		if n < o.Weight {
+			return o
+		}
+		n -= o.Weight
+	}
+	return nil
+}
+
+func (b *Balancer) evalHashKey(ctx *hcl.EvalContext) string {
+	v, diags := b.hashKey.Value(ctx)
+	if seetie.SetSeverityLevel(diags).HasErrors() {
+		return ""
+	}
+	return seetie.ValueToString(v)
+}
+
+func (b *Balancer) initRing() {
+	for _, o := range b.origins {
+		for i := 0; i < o.Weight*ringReplicas; i++ {
+			b.ring = append(b.ring, ringEntry{
+				hash:   hashString(o.URL.String() + "#" + strconv.Itoa(i)),
+				origin: o,
+			})
+		}
+	}
+	sort.Slice(b.ring, func(i, j int) bool {
+		return b.ring[i].hash < b.ring[j].hash
+	})
+}
+
+// lookup returns the origin of the first healthy hash ring entry following the given key.
+func (b *Balancer) lookup(key string) *Origin {
+	h := hashString(key)
+	idx := sort.Search(len(b.ring), func(i int) bool {
+		return b.ring[i].hash >= h
+	})
+
+	for i := 0; i < len(b.ring); i++ {
+		entry := b.ring[(idx+i)%len(b.ring)]
+		if entry.origin.IsHealthy() {
+			return entry.origin
+		}
+	}
+	return nil
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
@@ -0,0 +1,172 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/handler/transport"
+	"github.com/avenga/couper/internal/test"
+)
+
+func newTestOrigins(t *testing.T, weights ...int) []*transport.Origin {
+	var origins []*transport.Origin
+	for i, w := range weights {
+		o, err := transport.NewOrigin("http://origin"+string(rune('a'+i))+":8080", w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		origins = append(origins, o)
+	}
+	return origins
+}
+
+func TestBalancer_RoundRobin(t *testing.T) {
+	origins := newTestOrigins(t, 2, 1)
+	balancer, err := transport.NewBalancer(transport.StrategyRoundRobin, nil, origins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[*transport.Origin]int{}
+	for i := 0; i < 30; i++ {
+		counts[balancer.Next(nil)]++
+	}
+
+	if counts[origins[0]] != 20 || counts[origins[1]] != 10 {
+		t.Errorf("Expected weighted distribution 20/10, got: %d/%d", counts[origins[0]], counts[origins[1]])
+	}
+}
+
+func TestBalancer_Random(t *testing.T) {
+	origins := newTestOrigins(t, 1, 0)
+	balancer, err := transport.NewBalancer(transport.StrategyRandom, nil, origins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[*transport.Origin]bool{}
+	for i := 0; i < 100; i++ {
+		seen[balancer.Next(nil)] = true
+	}
+
+	if len(seen) != 2 {
+		t.Errorf("Expected both origins to be selected, got: %d", len(seen))
+	}
+}
+
+func TestBalancer_ConsistentHash(t *testing.T) {
+	origins := newTestOrigins(t, 1, 1, 1)
+
+	hashKey, diags := hclsyntax.ParseExpression([]byte(`req.headers.x-user-id`), "test.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	if _, err := transport.NewBalancer(transport.StrategyConsistentHash, nil, origins); err == nil {
+		t.Error("Expected a missing hash_key error")
+	}
+
+	balancer, err := transport.NewBalancer(transport.StrategyConsistentHash, hashKey, origins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newCtx := func(userID string) *hcl.EvalContext {
+		req := httptest.NewRequest(http.MethodGet, "http://couper.io/", nil)
+		req.Header.Set("X-User-ID", userID)
+		return eval.NewContext(nil).WithClientRequest(req).HCLContext()
+	}
+
+	seen := map[*transport.Origin]bool{}
+	for _, userID := range []string{"alice", "bob", "carol", "dave", "eve", "frank", "grace", "heidi"} {
+		selected := balancer.Next(newCtx(userID))
+		seen[selected] = true
+		for i := 0; i < 5; i++ {
+			if o := balancer.Next(newCtx(userID)); o != selected {
+				t.Errorf("Expected origin affinity for %q: want %s, got %s", userID, selected.URL, o.URL)
+			}
+		}
+	}
+
+	if len(seen) < 2 {
+		t.Error("Expected distribution over multiple origins")
+	}
+}
+
+func TestBalancer_LeastConnections(t *testing.T) {
+	blockCh := make(chan struct{})
+	var servers []*httptest.Server
+	var origins []*transport.Origin
+	for i := 0; i < 2; i++ {
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/block" {
+				<-blockCh
+			}
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+		servers = append(servers, srv)
+
+		o, err := transport.NewOrigin(srv.URL, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		origins = append(origins, o)
+	}
+
+	balancer, err := transport.NewBalancer(transport.StrategyLeastConnections, nil, origins)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger, _ := logrustest.NewNullLogger()
+	backend := transport.NewBackend(test.NewRemainContext("hostname", "couper.io"), &transport.Config{NoProxyFromEnv: true},
+		&transport.BackendOptions{Balancer: balancer}, logger.WithContext(context.Background()))
+
+	doneCh := make(chan struct{})
+	go func() {
+		res, rerr := backend.RoundTrip(httptest.NewRequest(http.MethodGet, "http://couper.io/block", nil))
+		if rerr == nil {
+			res.Body.Close()
+		}
+		close(doneCh)
+	}()
+
+	// wait for the blocking request
+	for origins[0].Connections()+origins[1].Connections() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	blocked := origins[0]
+	if origins[1].Connections() == 1 {
+		blocked = origins[1]
+	}
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "http://couper.io/", nil)
+		res, rerr := backend.RoundTrip(req)
+		if rerr != nil {
+			t.Fatal(rerr)
+		}
+		res.Body.Close()
+
+		if req.URL.Host == blocked.URL.Host {
+			t.Errorf("Expected the origin without running requests, got: %s", req.URL.Host)
+		}
+	}
+
+	close(blockCh)
+	<-doneCh
+
+	if c := blocked.Connections(); c != 0 {
+		t.Errorf("Expected released connections, got: %d", c)
+	}
+}
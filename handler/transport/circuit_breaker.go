@@ -0,0 +1,172 @@
+package transport
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	couperErr "github.com/avenga/couper/errors"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitHalfOpen = "half-open"
+	CircuitOpen     = "open"
+)
+
+// CircuitBreakerOptions represents the circuit breaker configuration of a backend.
+type CircuitBreakerOptions struct {
+	ConsecutiveFailures int
+	ErrorRate           float64
+	HalfOpenRequests    int
+	MinRequests         int
+	OpenTimeout         time.Duration
+	Window              time.Duration
+}
+
+// CircuitBreaker fails fast while the failure thresholds of
+// an origin are exceeded and probes its recovery afterwards.
+type CircuitBreaker struct {
+	log     *logrus.Entry
+	mu      sync.Mutex
+	now     func() time.Time
+	options *CircuitBreakerOptions
+	state   string
+
+	consecutiveFailures int
+	openedAt            time.Time
+	// error rate window
+	failures    int
+	requests    int
+	windowStart time.Time
+	// half-open probes
+	halfOpenRunning   int
+	halfOpenSuccesses int
+}
+
+// NewCircuitBreaker creates a new <*CircuitBreaker> object in closed state.
+func NewCircuitBreaker(opts *CircuitBreakerOptions, log *logrus.Entry) *CircuitBreaker {
+	return &CircuitBreaker{
+		log:     log,
+		now:     time.Now,
+		options: opts,
+		state:   CircuitClosed,
+	}
+}
+
+// State returns the current state.
+func (c *CircuitBreaker) State() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// Allow checks if a request may pass. Either the returned done function must be called with
+// the request result or, if the request has not been sent, the returned cancel function.
+// An open circuit results in the <errors.UpstreamCircuitOpen> error.
+func (c *CircuitBreaker) Allow() (func(failed bool), func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == CircuitOpen {
+		if c.now().Sub(c.openedAt) < c.options.OpenTimeout {
+			return nil, nil, couperErr.UpstreamCircuitOpen
+		}
+		c.setState(CircuitHalfOpen)
+	}
+
+	if c.state == CircuitHalfOpen {
+		if c.halfOpenRunning >= c.options.HalfOpenRequests {
+			return nil, nil, couperErr.UpstreamCircuitOpen
+		}
+		c.halfOpenRunning++
+		return c.doneHalfOpen, c.cancelHalfOpen, nil
+	}
+
+	return c.done, func() {}, nil
+}
+
+func (c *CircuitBreaker) done(failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != CircuitClosed { // already tripped by a concurrent request
+		return
+	}
+
+	now := c.now()
+	if c.options.Window > 0 && now.Sub(c.windowStart) >= c.options.Window {
+		c.windowStart = now
+		c.requests, c.failures = 0, 0
+	}
+
+	c.requests++
+	if failed {
+		c.failures++
+		c.consecutiveFailures++
+	} else {
+		c.consecutiveFailures = 0
+	}
+
+	if c.options.ConsecutiveFailures > 0 && c.consecutiveFailures >= c.options.ConsecutiveFailures {
+		c.open()
+		return
+	}
+
+	if c.options.ErrorRate > 0 && c.requests >= c.options.MinRequests &&
+		float64(c.failures)/float64(c.requests) >= c.options.ErrorRate {
+		c.open()
+	}
+}
+
+func (c *CircuitBreaker) doneHalfOpen(failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != CircuitHalfOpen {
+		return
+	}
+
+	c.halfOpenRunning--
+	if failed {
+		c.open()
+		return
+	}
+
+	c.halfOpenSuccesses++
+	if c.halfOpenSuccesses >= c.options.HalfOpenRequests {
+		c.setState(CircuitClosed)
+	}
+}
+
+// cancelHalfOpen releases a probe which has not been sent.
+func (c *CircuitBreaker) cancelHalfOpen() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == CircuitHalfOpen {
+		c.halfOpenRunning--
+	}
+}
+
+func (c *CircuitBreaker) open() {
+	c.openedAt = c.now()
+	c.setState(CircuitOpen)
+}
+
+// setState resets the counters and logs the transition. Must be called with the lock held.
+func (c *CircuitBreaker) setState(state string) {
+	entry := c.log.WithField("circuit_breaker", state)
+	if state == CircuitClosed {
+		entry.Infof("circuit breaker state changed from %s to %s", c.state, state)
+	} else {
+		entry.Warnf("circuit breaker state changed from %s to %s", c.state, state)
+	}
+
+	c.state = state
+	c.consecutiveFailures = 0
+	c.failures, c.requests = 0, 0
+	c.windowStart = c.now()
+	c.halfOpenRunning, c.halfOpenSuccesses = 0, 0
+}
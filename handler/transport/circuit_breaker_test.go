@@ -0,0 +1,156 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	couperErr "github.com/avenga/couper/errors"
+	"github.com/avenga/couper/handler/transport"
+	"github.com/avenga/couper/internal/test"
+)
+
+func TestCircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	logger, hook := logrustest.NewNullLogger()
+	cb := transport.NewCircuitBreaker(&transport.CircuitBreakerOptions{
+		ConsecutiveFailures: 3,
+		HalfOpenRequests:    1,
+		OpenTimeout:         time.Second / 4,
+	}, logger.WithContext(context.Background()))
+
+	for i, failed := range []bool{true, true, false, true, true, true} {
+		done, _, err := cb.Allow()
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+		done(failed)
+	}
+
+	if state := cb.State(); state != transport.CircuitOpen {
+		t.Fatalf("expected state %q, got: %q", transport.CircuitOpen, state)
+	}
+
+	if _, _, err := cb.Allow(); err != couperErr.UpstreamCircuitOpen {
+		t.Fatalf("expected circuit open error, got: %v", err)
+	}
+
+	if entries := hook.AllEntries(); len(entries) != 1 || entries[0].Data["circuit_breaker"] != transport.CircuitOpen {
+		t.Errorf("expected one state change log entry, got: %v", entries)
+	}
+
+	time.Sleep(time.Second / 4)
+
+	_, cancel, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected half-open probe, got: %v", err)
+	}
+
+	// a canceled probe frees its slot
+	cancel()
+
+	probe, _, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected half-open probe, got: %v", err)
+	}
+	if state := cb.State(); state != transport.CircuitHalfOpen {
+		t.Fatalf("expected state %q, got: %q", transport.CircuitHalfOpen, state)
+	}
+
+	if _, _, err = cb.Allow(); err != couperErr.UpstreamCircuitOpen {
+		t.Fatalf("expected exceeded half-open requests error, got: %v", err)
+	}
+
+	probe(false)
+
+	if state := cb.State(); state != transport.CircuitClosed {
+		t.Fatalf("expected state %q, got: %q", transport.CircuitClosed, state)
+	}
+}
+
+func TestCircuitBreaker_ErrorRate(t *testing.T) {
+	logger, _ := logrustest.NewNullLogger()
+	cb := transport.NewCircuitBreaker(&transport.CircuitBreakerOptions{
+		ErrorRate:        0.5,
+		HalfOpenRequests: 1,
+		MinRequests:      4,
+		OpenTimeout:      time.Second / 4,
+		Window:           time.Minute,
+	}, logger.WithContext(context.Background()))
+
+	for i, failed := range []bool{true, false, true} {
+		done, _, err := cb.Allow()
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+		done(failed)
+	}
+
+	// below min_requests
+	if state := cb.State(); state != transport.CircuitClosed {
+		t.Fatalf("expected state %q, got: %q", transport.CircuitClosed, state)
+	}
+
+	done, _, _ := cb.Allow()
+	done(false) // 2 of 4 failed
+
+	if state := cb.State(); state != transport.CircuitOpen {
+		t.Fatalf("expected state %q, got: %q", transport.CircuitOpen, state)
+	}
+
+	time.Sleep(time.Second / 4)
+
+	probe, _, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected half-open probe, got: %v", err)
+	}
+	probe(true)
+
+	if state := cb.State(); state != transport.CircuitOpen {
+		t.Fatalf("expected state %q after failed probe, got: %q", transport.CircuitOpen, state)
+	}
+}
+
+func TestBackend_RoundTrip_CircuitBreaker(t *testing.T) {
+	var requests int32
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer origin.Close()
+
+	logger, _ := logrustest.NewNullLogger()
+	log := logger.WithContext(context.Background())
+
+	backend := transport.NewBackend(test.NewRemainContext("origin", origin.URL), &transport.Config{}, &transport.BackendOptions{
+		CircuitBreaker: transport.NewCircuitBreaker(&transport.CircuitBreakerOptions{
+			ConsecutiveFailures: 2,
+			HalfOpenRequests:    1,
+			OpenTimeout:         time.Minute,
+		}, log),
+	}, log)
+
+	for i := 0; i < 2; i++ {
+		res, err := backend.RoundTrip(httptest.NewRequest(http.MethodGet, "http://couper.io/", nil))
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+		if res.StatusCode != http.StatusBadGateway {
+			t.Errorf("request %d: expected status %d, got: %d", i, http.StatusBadGateway, res.StatusCode)
+		}
+		_ = res.Body.Close()
+	}
+
+	_, err := backend.RoundTrip(httptest.NewRequest(http.MethodGet, "http://couper.io/", nil))
+	if err != couperErr.UpstreamCircuitOpen {
+		t.Errorf("expected circuit open error, got: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 origin requests, got: %d", n)
+	}
+}
@@ -0,0 +1,158 @@
+package transport
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/eval"
+)
+
+var _ eval.BackendHealth = &HealthCheck{}
+
+// HealthCheckOptions represents the active health check configuration of a backend.
+type HealthCheckOptions struct {
+	ExpectedStatus   []int
+	FailureThreshold int
+	Interval         time.Duration
+	Path             string
+	SuccessThreshold int
+	Timeout          time.Duration
+}
+
+// HealthCheck probes the origins of a backend in the background
+// and marks them as healthy or unhealthy.
+type HealthCheck struct {
+	balancer      *Balancer
+	hostname      string
+	log           *logrus.Entry
+	options       *HealthCheckOptions
+	transportConf *Config
+}
+
+// NewHealthCheck creates a new <*HealthCheck> object for all origins of the given balancer.
+// An empty hostname results in the origin host for the probe requests.
+func NewHealthCheck(opts *HealthCheckOptions, balancer *Balancer, tc *Config, hostname string, log *logrus.Entry) *HealthCheck {
+	return &HealthCheck{
+		balancer:      balancer,
+		hostname:      hostname,
+		log:           log.WithField("backend", tc.BackendName),
+		options:       opts,
+		transportConf: tc,
+	}
+}
+
+// Balancer returns the balancer whose origins are probed.
+func (h *HealthCheck) Balancer() *Balancer {
+	return h.balancer
+}
+
+// Key returns an identifier of the probed origins and the health check configuration.
+func (h *HealthCheck) Key() string {
+	hash := sha256.New()
+	for _, o := range h.balancer.Origins() {
+		_, _ = fmt.Fprintf(hash, "%s;%d;", o.URL, o.Weight)
+	}
+	_, _ = fmt.Fprintf(hash, "%s;%v", h.hostname, *h.options)
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// HealthState implements the <eval.BackendHealth> interface.
+func (h *HealthCheck) HealthState() eval.HealthState {
+	var healthy int
+	origins := h.balancer.Origins()
+	for _, o := range origins {
+		if o.IsHealthy() {
+			healthy++
+		}
+	}
+
+	switch healthy {
+	case len(origins):
+		return eval.HealthState{Healthy: true, State: eval.StateHealthy}
+	case 0:
+		return eval.HealthState{Healthy: false, State: eval.StateUnhealthy}
+	default:
+		return eval.HealthState{Healthy: true, State: eval.StateDegraded}
+	}
+}
+
+// Start implements the <eval.BackendHealth> interface.
+func (h *HealthCheck) Start(ctx context.Context) {
+	for _, o := range h.balancer.Origins() {
+		go h.watch(ctx, o)
+	}
+}
+
+func (h *HealthCheck) watch(ctx context.Context, origin *Origin) {
+	ticker := time.NewTicker(h.options.Interval)
+	defer ticker.Stop()
+
+	var failures, successes int
+	for {
+		err := h.probe(ctx, origin)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			failures++
+			successes = 0
+			if origin.IsHealthy() && failures >= h.options.FailureThreshold {
+				origin.setHealthy(false)
+				h.log.WithField("origin", origin.URL.String()).Warnf("origin is unhealthy: %v", err)
+			}
+		} else {
+			successes++
+			failures = 0
+			if !origin.IsHealthy() && successes >= h.options.SuccessThreshold {
+				origin.setHealthy(true)
+				h.log.WithField("origin", origin.URL.String()).Info("origin is healthy")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *HealthCheck) probe(ctx context.Context, origin *Origin) error {
+	hostname := h.hostname
+	if hostname == "" {
+		hostname = origin.URL.Host
+	}
+	tc := h.transportConf.With(origin.URL.Scheme, origin.URL.Host, hostname)
+
+	probeCtx, cancel := context.WithTimeout(ctx, h.options.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, tc.Scheme+"://"+tc.Origin+h.options.Path, nil)
+	if err != nil {
+		return err
+	}
+	req.Host = tc.Hostname
+	req.Header.Set("User-Agent", "couper health-check")
+
+	res, err := Get(tc).RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	_ = res.Body.Close()
+
+	for _, status := range h.options.ExpectedStatus {
+		if res.StatusCode == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected status: %d", res.StatusCode)
+}
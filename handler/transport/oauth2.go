@@ -0,0 +1,161 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avenga/couper/config/request"
+)
+
+const (
+	AuthMethodClientSecretBasic = "client_secret_basic"
+	AuthMethodClientSecretPost  = "client_secret_post"
+
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// tokenExpiryDelta renews tokens shortly before their expiry to cover the request duration.
+// Short-lived tokens are renewed after half of their lifetime instead.
+const tokenExpiryDelta = time.Second * 10
+
+// OAuth2Options represents the oauth2 client configuration of a backend.
+type OAuth2Options struct {
+	AuthMethod    string
+	ClientID      string
+	ClientSecret  string
+	Scope         string
+	TokenEndpoint string
+}
+
+// OAuth2 obtains access tokens with the client credentials grant and caches them until their expiry.
+type OAuth2 struct {
+	backend http.RoundTripper
+	mu      sync.Mutex
+	options *OAuth2Options
+
+	expiresAt time.Time
+	token     string
+}
+
+// NewOAuth2 creates a new <*OAuth2> object. The token requests are sent via the given backend.
+func NewOAuth2(opts *OAuth2Options, backend http.RoundTripper) (*OAuth2, error) {
+	switch opts.AuthMethod {
+	case "":
+		opts.AuthMethod = AuthMethodClientSecretBasic
+	case AuthMethodClientSecretBasic, AuthMethodClientSecretPost:
+	default:
+		return nil, fmt.Errorf("oauth2: unsupported token_endpoint_auth_method: %q", opts.AuthMethod)
+	}
+
+	if _, err := url.ParseRequestURI(opts.TokenEndpoint); err != nil {
+		return nil, fmt.Errorf("oauth2: invalid token_endpoint: %v", err)
+	}
+
+	return &OAuth2{backend: backend, options: opts}, nil
+}
+
+// Token returns the cached access token or requests a new one.
+func (o *OAuth2) Token(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != "" && (o.expiresAt.IsZero() || time.Now().Before(o.expiresAt)) {
+		return o.token, nil
+	}
+
+	token, expiresIn, err := o.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	o.token = token
+	o.expiresAt = time.Time{}
+	if expiresIn > 0 {
+		expiry, delta := time.Duration(expiresIn)*time.Second, tokenExpiryDelta
+		if expiry/2 < delta {
+			delta = expiry / 2
+		}
+		o.expiresAt = time.Now().Add(expiry - delta)
+	}
+
+	return o.token, nil
+}
+
+// Invalidate removes the given token from the cache, e.g. after it has been rejected by the origin.
+func (o *OAuth2) Invalidate(token string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token == token {
+		o.token = ""
+	}
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+func (o *OAuth2) requestToken(ctx context.Context) (string, int64, error) {
+	form := url.Values{}
+	form.Set("grant_type", GrantTypeClientCredentials)
+	if o.options.Scope != "" {
+		form.Set("scope", o.options.Scope)
+	}
+	if o.options.AuthMethod == AuthMethodClientSecretPost {
+		form.Set("client_id", o.options.ClientID)
+		form.Set("client_secret", o.options.ClientSecret)
+	}
+
+	outCtx := context.WithValue(ctx, request.RoundTripName, "oauth2")
+	req, err := http.NewRequestWithContext(outCtx, http.MethodPost, o.options.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if o.options.AuthMethod == AuthMethodClientSecretBasic {
+		// RFC 6749, section 2.3.1
+		req.SetBasicAuth(url.QueryEscape(o.options.ClientID), url.QueryEscape(o.options.ClientSecret))
+	}
+
+	res, err := o.backend.RoundTrip(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return "", 0, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("oauth2: unexpected token response status: %d", res.StatusCode)
+	}
+
+	tr := &tokenResponse{}
+	if err = json.Unmarshal(body, tr); err != nil {
+		return "", 0, fmt.Errorf("oauth2: invalid token response: %v", err)
+	}
+
+	if tr.AccessToken == "" {
+		return "", 0, fmt.Errorf("oauth2: missing access_token in token response")
+	}
+
+	if tr.TokenType != "" && !strings.EqualFold(tr.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("oauth2: unsupported token_type: %q", tr.TokenType)
+	}
+
+	return tr.AccessToken, tr.ExpiresIn, nil
+}
@@ -0,0 +1,203 @@
+package transport_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	couperErr "github.com/avenga/couper/errors"
+	"github.com/avenga/couper/handler/transport"
+	"github.com/avenga/couper/internal/test"
+)
+
+func newTokenEndpoint(t *testing.T, authMethod string, expiresIn int) (*httptest.Server, *int32) {
+	var tokens int32
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if err := req.ParseForm(); err != nil {
+			t.Error(err)
+		}
+
+		if gt := req.PostForm.Get("grant_type"); gt != "client_credentials" {
+			t.Errorf("expected client_credentials grant_type, got: %q", gt)
+		}
+
+		clientID, clientSecret, ok := req.BasicAuth()
+		if authMethod == transport.AuthMethodClientSecretPost {
+			clientID, clientSecret, ok = req.PostForm.Get("client_id"), req.PostForm.Get("client_secret"), true
+		}
+
+		if !ok || clientID != "my-client" || clientSecret != "my-secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		n := atomic.AddInt32(&tokens, 1)
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(rw, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d,"scope":%q}`,
+			n, expiresIn, req.PostForm.Get("scope"))
+	})), &tokens
+}
+
+func TestBackend_RoundTrip_OAuth2(t *testing.T) {
+	logger, _ := logrustest.NewNullLogger()
+	log := logger.WithContext(context.Background())
+
+	var revoked int32
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		auth := req.Header.Get("Authorization")
+		if atomic.LoadInt32(&revoked) == 1 && auth == "Bearer token-1" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		rw.Header().Set("X-Body", string(body))
+		rw.Header().Set("X-Auth", auth)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer origin.Close()
+
+	for _, authMethod := range []string{transport.AuthMethodClientSecretBasic, transport.AuthMethodClientSecretPost} {
+		t.Run(authMethod, func(subT *testing.T) {
+			atomic.StoreInt32(&revoked, 0)
+
+			tokenEndpoint, tokens := newTokenEndpoint(subT, authMethod, 3600)
+			defer tokenEndpoint.Close()
+
+			tokenBackend := transport.NewBackend(test.NewRemainContext("origin", tokenEndpoint.URL), &transport.Config{}, nil, log)
+			oauth2, err := transport.NewOAuth2(&transport.OAuth2Options{
+				AuthMethod:    authMethod,
+				ClientID:      "my-client",
+				ClientSecret:  "my-secret",
+				Scope:         "read write",
+				TokenEndpoint: tokenEndpoint.URL + "/oauth/token",
+			}, tokenBackend)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			backend := transport.NewBackend(test.NewRemainContext("origin", origin.URL), &transport.Config{}, &transport.BackendOptions{
+				OAuth2: oauth2,
+			}, log)
+
+			for i, expToken := range []string{"token-1", "token-1"} {
+				res, rerr := backend.RoundTrip(httptest.NewRequest(http.MethodGet, "http://couper.io/", nil))
+				if rerr != nil {
+					subT.Fatal(rerr)
+				}
+				if auth := res.Header.Get("X-Auth"); auth != "Bearer "+expToken {
+					subT.Errorf("request %d: expected token %q, got: %q", i, expToken, auth)
+				}
+			}
+
+			if n := atomic.LoadInt32(tokens); n != 1 {
+				subT.Errorf("expected one cached token, got %d token requests", n)
+			}
+
+			// origin rejects the cached token
+			atomic.StoreInt32(&revoked, 1)
+
+			req := httptest.NewRequest(http.MethodPost, "http://couper.io/", strings.NewReader("payload"))
+			req.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(strings.NewReader("payload")), nil
+			}
+			res, err := backend.RoundTrip(req)
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			if res.StatusCode != http.StatusNoContent {
+				subT.Errorf("expected status %d after token renewal, got: %d", http.StatusNoContent, res.StatusCode)
+			}
+			if auth := res.Header.Get("X-Auth"); auth != "Bearer token-2" {
+				subT.Errorf("expected renewed token, got: %q", auth)
+			}
+			if body := res.Header.Get("X-Body"); body != "payload" {
+				subT.Errorf("expected replayed request body, got: %q", body)
+			}
+		})
+	}
+}
+
+func TestBackend_RoundTrip_OAuth2_Expiry(t *testing.T) {
+	logger, _ := logrustest.NewNullLogger()
+	log := logger.WithContext(context.Background())
+
+	// expires within the renewal delta, renewed after half of the lifetime
+	tokenEndpoint, tokens := newTokenEndpoint(t, transport.AuthMethodClientSecretBasic, 1)
+	defer tokenEndpoint.Close()
+
+	tokenBackend := transport.NewBackend(test.NewRemainContext("origin", tokenEndpoint.URL), &transport.Config{}, nil, log)
+	oauth2, err := transport.NewOAuth2(&transport.OAuth2Options{
+		ClientID:      "my-client",
+		ClientSecret:  "my-secret",
+		TokenEndpoint: tokenEndpoint.URL + "/oauth/token",
+	}, tokenBackend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, exp := range []string{"token-1", "token-1", "", "token-2"} {
+		if exp == "" {
+			time.Sleep(time.Millisecond * 600)
+			continue
+		}
+
+		token, terr := oauth2.Token(context.Background())
+		if terr != nil {
+			t.Fatal(terr)
+		}
+		if token != exp {
+			t.Errorf("step %d: expected token %q, got: %q", i, exp, token)
+		}
+	}
+
+	if n := atomic.LoadInt32(tokens); n != 2 {
+		t.Errorf("expected 2 token requests, got: %d", n)
+	}
+}
+
+func TestBackend_RoundTrip_OAuth2_TokenRequestFailed(t *testing.T) {
+	logger, hook := logrustest.NewNullLogger()
+	log := logger.WithContext(context.Background())
+
+	tokenEndpoint, _ := newTokenEndpoint(t, transport.AuthMethodClientSecretBasic, 60)
+	defer tokenEndpoint.Close()
+
+	tokenBackend := transport.NewBackend(test.NewRemainContext("origin", tokenEndpoint.URL), &transport.Config{}, nil, log)
+	oauth2, err := transport.NewOAuth2(&transport.OAuth2Options{
+		ClientID:      "my-client",
+		ClientSecret:  "wrong-secret",
+		TokenEndpoint: tokenEndpoint.URL + "/oauth/token",
+	}, tokenBackend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := transport.NewBackend(test.NewRemainContext("origin", "http://1.2.3.4"), &transport.Config{}, &transport.BackendOptions{
+		OAuth2: oauth2,
+	}, log)
+
+	_, err = backend.RoundTrip(httptest.NewRequest(http.MethodGet, "http://couper.io/", nil))
+	if err != couperErr.UpstreamTokenRequestFailed {
+		t.Errorf("expected token request error, got: %v", err)
+	}
+
+	var logged bool
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "oauth2 token request failed" {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Error("expected a logged token request error")
+	}
+}
@@ -0,0 +1,102 @@
+package transport
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	couperErr "github.com/avenga/couper/errors"
+)
+
+// ThrottleOptions represents the throttle configuration of a backend.
+type ThrottleOptions struct {
+	Burst             int
+	MaxQueue          int
+	MaxWait           time.Duration
+	RequestsPerSecond float64
+}
+
+// Throttle limits the outgoing requests of a backend to the configured rate. Requests
+// exceeding the rate are queued, up to MaxQueue requests for at most MaxWait each.
+type Throttle struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	options *ThrottleOptions
+
+	queued    int
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewThrottle creates a new <*Throttle> object with a full burst.
+func NewThrottle(opts *ThrottleOptions) *Throttle {
+	return &Throttle{
+		now:       time.Now,
+		options:   opts,
+		tokens:    float64(opts.Burst),
+		updatedAt: time.Now(),
+	}
+}
+
+// Wait blocks until the request may be sent and returns the queue depth including the
+// request and the waiting time. Requests which would exceed the queue length or the
+// maximum waiting time fail with the <errors.UpstreamThrottled> error.
+func (t *Throttle) Wait(ctx context.Context) (int, time.Duration, error) {
+	t.mu.Lock()
+	now := t.now()
+	if elapsed := now.Sub(t.updatedAt); elapsed > 0 {
+		t.tokens += elapsed.Seconds() * t.options.RequestsPerSecond
+		if burst := float64(t.options.Burst); t.tokens > burst {
+			t.tokens = burst
+		}
+		t.updatedAt = now
+	}
+
+	if t.tokens >= 1 {
+		t.tokens--
+		t.mu.Unlock()
+		return 0, 0, nil
+	}
+
+	if t.queued >= t.options.MaxQueue {
+		depth := t.queued
+		t.mu.Unlock()
+		return depth, 0, couperErr.UpstreamThrottled
+	}
+
+	wait := time.Duration((1 - t.tokens) / t.options.RequestsPerSecond * float64(time.Second))
+	if wait > t.options.MaxWait {
+		depth := t.queued
+		t.mu.Unlock()
+		return depth, 0, couperErr.UpstreamThrottled
+	}
+
+	// reserve the next token, the following requests queue up behind this one
+	t.tokens--
+	t.queued++
+	depth := t.queued
+	t.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		t.dequeue(false)
+		return depth, wait, nil
+	case <-ctx.Done():
+		t.dequeue(true)
+		return depth, t.now().Sub(now), ctx.Err()
+	}
+}
+
+// dequeue removes a request from the queue, a canceled one returns its reserved token.
+func (t *Throttle) dequeue(canceled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.queued--
+	if canceled {
+		t.tokens++
+	}
+}
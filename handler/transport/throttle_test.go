@@ -0,0 +1,159 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+
+	couperErr "github.com/avenga/couper/errors"
+	"github.com/avenga/couper/handler/transport"
+	"github.com/avenga/couper/internal/test"
+	"github.com/avenga/couper/logging"
+)
+
+func TestThrottle_Wait(t *testing.T) {
+	throttle := transport.NewThrottle(&transport.ThrottleOptions{
+		Burst:             1,
+		MaxQueue:          1,
+		MaxWait:           time.Second,
+		RequestsPerSecond: 10,
+	})
+
+	if depth, wait, err := throttle.Wait(context.Background()); depth != 0 || wait != 0 || err != nil {
+		t.Fatalf("expected the burst to pass, got: %d, %s, %v", depth, wait, err)
+	}
+
+	type result struct {
+		depth int
+		wait  time.Duration
+		err   error
+	}
+	queued := make(chan result)
+	go func() {
+		depth, wait, err := throttle.Wait(context.Background())
+		queued <- result{depth, wait, err}
+	}()
+
+	time.Sleep(time.Millisecond * 20)
+	if _, _, err := throttle.Wait(context.Background()); err != couperErr.UpstreamThrottled {
+		t.Errorf("expected a full queue, got: %v", err)
+	}
+
+	r := <-queued
+	if r.err != nil || r.depth != 1 || r.wait <= 0 || r.wait > time.Millisecond*100 {
+		t.Errorf("expected a queued request, got: %d, %s, %v", r.depth, r.wait, r.err)
+	}
+
+	// canceled requests return their token
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if _, _, err := throttle.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected the context error, got: %v", err)
+	}
+
+	if _, wait, err := throttle.Wait(context.Background()); err != nil || wait > time.Millisecond*100 {
+		t.Errorf("expected the returned token, got: %s, %v", wait, err)
+	}
+}
+
+func TestThrottle_MaxWait(t *testing.T) {
+	throttle := transport.NewThrottle(&transport.ThrottleOptions{
+		Burst:             2,
+		MaxQueue:          10,
+		MaxWait:           time.Millisecond * 100,
+		RequestsPerSecond: 2,
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := throttle.Wait(context.Background()); err != nil {
+			t.Fatalf("request %d: expected the burst to pass, got: %v", i, err)
+		}
+	}
+
+	start := time.Now()
+	if _, _, err := throttle.Wait(context.Background()); err != couperErr.UpstreamThrottled {
+		t.Errorf("expected an exceeded max wait, got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Millisecond*50 {
+		t.Errorf("expected an immediate failure, got: %s", elapsed)
+	}
+}
+
+func TestBackend_RoundTrip_Throttle(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer origin.Close()
+
+	logger, hook := logrustest.NewNullLogger()
+	log := logger.WithContext(context.Background())
+
+	backend := transport.NewBackend(test.NewRemainContext("origin", origin.URL), &transport.Config{}, &transport.BackendOptions{
+		Throttle: transport.NewThrottle(&transport.ThrottleOptions{
+			Burst:             1,
+			MaxQueue:          1,
+			MaxWait:           time.Second,
+			RequestsPerSecond: 20,
+		}),
+	}, log)
+
+	for i, wantQueue := range []int{0, 1} {
+		hook.Reset()
+
+		res, err := backend.RoundTrip(httptest.NewRequest(http.MethodGet, "http://couper.io/", nil))
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+		_ = res.Body.Close()
+
+		timings, _ := hook.LastEntry().Data["timings"].(logging.Fields)
+		if timings["throttle_queue"] != wantQueue {
+			t.Errorf("request %d: expected throttle_queue %d, got: %v", i, wantQueue, timings["throttle_queue"])
+		}
+
+		if _, exist := timings["throttle_wait"]; !exist {
+			t.Errorf("request %d: expected a throttle_wait timing, got: %v", i, timings)
+		}
+	}
+}
+
+func TestBackend_RoundTrip_ThrottleCircuitBreaker(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer origin.Close()
+
+	logger, _ := logrustest.NewNullLogger()
+	log := logger.WithContext(context.Background())
+
+	backend := transport.NewBackend(test.NewRemainContext("origin", origin.URL), &transport.Config{}, &transport.BackendOptions{
+		CircuitBreaker: transport.NewCircuitBreaker(&transport.CircuitBreakerOptions{
+			ConsecutiveFailures: 1,
+			HalfOpenRequests:    1,
+			OpenTimeout:         time.Minute,
+		}, log),
+		Throttle: transport.NewThrottle(&transport.ThrottleOptions{
+			Burst:             1,
+			MaxQueue:          1,
+			MaxWait:           time.Millisecond * 10,
+			RequestsPerSecond: 1,
+		}),
+	}, log)
+
+	res, err := backend.RoundTrip(httptest.NewRequest(http.MethodGet, "http://couper.io/", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = res.Body.Close()
+
+	// the open circuit fails fast before the throttle
+	_, err = backend.RoundTrip(httptest.NewRequest(http.MethodGet, "http://couper.io/", nil))
+	if err != couperErr.UpstreamCircuitOpen {
+		t.Errorf("expected circuit open error, got: %v", err)
+	}
+}
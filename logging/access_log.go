@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/avenga/couper/config/request"
 	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/utils"
 )
 
 type RoundtripHandlerFunc http.HandlerFunc
@@ -108,6 +110,14 @@ func (log *AccessLog) ServeHTTP(rw http.ResponseWriter, req *http.Request, nextH
 	fields["scheme"] = "http"
 	if req.URL.Scheme != "" {
 		fields["scheme"] = req.URL.Scheme
+	} else if req.TLS != nil {
+		fields["scheme"] = "https"
+	}
+
+	if req.TLS != nil {
+		requestFields["tls_cipher_suite"] = tls.CipherSuiteName(req.TLS.CipherSuite)
+		requestFields["tls_server_name"] = req.TLS.ServerName
+		requestFields["tls_version"] = utils.TLSVersionName(req.TLS.Version)
 	}
 	if requestFields["port"] == "" {
 		if fields["scheme"] == "https" {
@@ -120,7 +130,13 @@ func (log *AccessLog) ServeHTTP(rw http.ResponseWriter, req *http.Request, nextH
 	fields["url"] = fields["scheme"].(string) + "://" + req.Host + path.String()
 
 	var err error
-	fields["client_ip"], _ = splitHostPort(req.RemoteAddr)
+	fields["client_ip"] = utils.ClientIP(req)
+	if granted, ok := req.Context().Value(request.AccessControlsGranted).([]string); ok {
+		fields["granted_by"] = granted
+	}
+	if names, ok := req.Context().Value(request.RateLimitFallback).([]string); ok {
+		fields["rate_limit_fallback"] = names
+	}
 	if couperErr := statusRecorder.Header().Get(errors.HeaderErrorCode); couperErr != "" {
 		i, _ := strconv.Atoi(couperErr[:4])
 		err = errors.Code(i)
@@ -135,6 +151,10 @@ func (log *AccessLog) ServeHTTP(rw http.ResponseWriter, req *http.Request, nextH
 	}
 	entry.Time = startTime
 
+	if acErr, ok := req.Context().Value(request.AccessControlError).(error); ok {
+		entry = entry.WithError(acErr)
+	}
+
 	if statusRecorder.status == http.StatusInternalServerError || err != nil {
 		if err != nil {
 			entry.Error(err)
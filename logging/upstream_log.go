@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net/http"
@@ -63,6 +64,10 @@ func (u *UpstreamLog) RoundTrip(req *http.Request) (*http.Response, error) {
 		requestFields["bytes"] = req.ContentLength
 	}
 
+	if attempt, ok := req.Context().Value(request.RoundTripAttempt).(int); ok {
+		requestFields["attempt"] = attempt
+	}
+
 	path := &url.URL{
 		Path:       req.URL.Path,
 		RawPath:    req.URL.RawPath,
@@ -101,6 +106,7 @@ func (u *UpstreamLog) RoundTrip(req *http.Request) (*http.Response, error) {
 	} else if user, _, ok := req.BasicAuth(); ok && user != "" {
 		fields["auth_user"] = user
 	}
+	requestFields["origin"] = req.URL.Host
 	requestFields["proto"] = req.Proto
 	requestFields["scheme"] = req.URL.Scheme
 
@@ -158,12 +164,38 @@ func (u *UpstreamLog) RoundTrip(req *http.Request) (*http.Response, error) {
 	return beresp, err
 }
 
+// LogEntry returns the log entry for backend related messages
+// like validation errors or circuit breaker state changes.
 func (u *UpstreamLog) LogEntry() *logrus.Entry {
-	// TODO: field enrichment / copy
-	// used for validation errors
+	if u.config.TypeFieldKey != "" {
+		return u.log.WithField("type", u.config.TypeFieldKey)
+	}
 	return u.log
 }
 
+// upstreamTimings holds the timings of an upstream roundtrip, see <SetUpstreamTiming>.
+type upstreamTimings struct {
+	fields Fields
+	mu     *sync.RWMutex
+}
+
+// SetUpstreamTiming adds a timing to the upstream log entry of the given request context,
+// e.g. the throttle wait of a backend. Durations are logged in milliseconds.
+func SetUpstreamTiming(ctx context.Context, name string, value interface{}) {
+	t, ok := ctx.Value(request.UpstreamTimings).(*upstreamTimings)
+	if !ok {
+		return
+	}
+
+	if d, ok := value.(time.Duration); ok {
+		value = roundMS(d)
+	}
+
+	t.mu.Lock()
+	t.fields[name] = value
+	t.mu.Unlock()
+}
+
 func (u *UpstreamLog) withTraceContext(req *http.Request) (Fields, *sync.RWMutex) {
 	timings := Fields{}
 	mapMu := &sync.RWMutex{}
@@ -220,6 +252,7 @@ func (u *UpstreamLog) withTraceContext(req *http.Request) (Fields, *sync.RWMutex
 		},
 	}
 	ctx := httptrace.WithClientTrace(req.Context(), trace)
+	ctx = context.WithValue(ctx, request.UpstreamTimings, &upstreamTimings{fields: timings, mu: mapMu})
 	*req = *req.WithContext(ctx)
 	return timings, mapMu
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -32,6 +33,10 @@ func Test_realmain(t *testing.T) {
 		{"common log format via env /w file", []string{"couper", "run", "-f", base + "/log_json.hcl"}, []string{"COUPER_LOG_FORMAT=common"}, `level=error msg="configuration error: missing server definition" build=dev`, 1},
 		// TODO: format from file currently not possible due to the server error
 		{"json log format via env /w file", []string{"couper", "run", "-f", base + "/log_common.hcl"}, []string{"COUPER_LOG_FORMAT=json"}, `{"build":"dev","level":"error","message":"configuration error: missing server definition"`, 1},
+		{"verify valid file", []string{"couper", "verify", "-f", "server/testdata/verify/valid.hcl"}, nil, `level=info msg="configuration is valid: ` + filepath.Join(wd, "server/testdata/verify/valid.hcl") + `"`, 0},
+		{"verify missing backend", []string{"couper", "verify", "-f", "server/testdata/verify/missing_backend.hcl"}, nil, `level=error msg="missing_backend.hcl:3,11-11: backend reference 'anything' is not defined; " build=dev`, 1},
+		{"verify syntax error", []string{"couper", "verify", "-f", "server/testdata/verify/syntax.hcl"}, nil, `level=error msg="syntax.hcl:3,1-1: Argument or block definition required;`, 1},
+		{"verify server error", []string{"couper", "verify", "-f", base + "/log_common.hcl"}, nil, `level=error msg="configuration error: missing server definition" build=dev`, 1},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2,9 +2,12 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"net"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/xid"
@@ -26,18 +29,24 @@ import (
 type HTTPServer struct {
 	accessLog  *logging.AccessLog
 	commandCtx context.Context
-	evalCtx    *eval.Context
 	listener   net.Listener
 	log        logrus.FieldLogger
-	mux        *Mux
 	port       string
 	settings   *config.Settings
 	shutdownCh chan struct{}
 	srv        *http.Server
+	state      atomic.Value // *serverState
 	timings    *runtime.HTTPTimings
 	uidFn      func() string
 }
 
+// serverState holds the parts of a HTTPServer which
+// could be replaced with a reloaded configuration.
+type serverState struct {
+	evalCtx *eval.Context
+	mux     *Mux
+}
+
 // NewServerList creates a list of all configured HTTP server.
 func NewServerList(cmdCtx context.Context, evalCtx *eval.Context, log logrus.FieldLogger, settings *config.Settings, timings *runtime.HTTPTimings, srvConf runtime.ServerConfiguration) ([]*HTTPServer, func()) {
 	var list []*HTTPServer
@@ -71,23 +80,17 @@ func New(cmdCtx context.Context, evalCtx *eval.Context, log logrus.FieldLogger,
 	logConf.TypeFieldKey = "couper_access"
 	env.DecodeWithPrefix(&logConf, "ACCESS_")
 
-	shutdownCh := make(chan struct{})
-
-	mux := NewMux(muxOpts)
-	mux.MustAddRoute(http.MethodGet, settings.HealthPath, handler.NewHealthCheck(settings.HealthPath, shutdownCh))
-
 	httpSrv := &HTTPServer{
-		evalCtx:    evalCtx,
 		accessLog:  logging.NewAccessLog(&logConf, log),
 		commandCtx: cmdCtx,
 		log:        log,
-		mux:        mux,
 		port:       p.String(),
 		settings:   settings,
-		shutdownCh: shutdownCh,
+		shutdownCh: make(chan struct{}),
 		timings:    timings,
 		uidFn:      uidFn,
 	}
+	httpSrv.setState(evalCtx, muxOpts)
 
 	srv := &http.Server{
 		Addr:              ":" + p.String(),
@@ -96,6 +99,12 @@ func New(cmdCtx context.Context, evalCtx *eval.Context, log logrus.FieldLogger,
 		ReadHeaderTimeout: timings.ReadHeaderTimeout,
 	}
 
+	if muxOpts.IsTLS() {
+		srv.TLSConfig = newTLSConfig(func() map[string]*tls.Certificate {
+			return httpSrv.getState().mux.opts.TLSCertificates
+		}, muxOpts.TLSRequestClientCert)
+	}
+
 	httpSrv.srv = srv
 
 	return httpSrv
@@ -109,6 +118,34 @@ func (s *HTTPServer) Addr() string {
 	return ""
 }
 
+// canReload verifies that the given options are applicable
+// without a modification of the listener configuration.
+func (s *HTTPServer) canReload(muxOpts *runtime.MuxOptions) error {
+	current := s.getState().mux.opts
+	if current.IsTLS() != muxOpts.IsTLS() || current.TLSRequestClientCert != muxOpts.TLSRequestClientCert {
+		return fmt.Errorf("port %s: changed tls configuration requires a restart", s.port)
+	}
+	return nil
+}
+
+func (s *HTTPServer) getState() *serverState {
+	return s.state.Load().(*serverState)
+}
+
+func (s *HTTPServer) setState(evalCtx *eval.Context, muxOpts *runtime.MuxOptions) {
+	health := handler.NewHealthCheck(s.settings.HealthPath, s.shutdownCh)
+	if s.settings.HealthIncludeBackends {
+		health = health.WithBackends(evalCtx.Backends())
+	}
+
+	mux := NewMux(muxOpts)
+	mux.MustAddRoute(http.MethodGet, s.settings.HealthPath, health)
+	s.state.Store(&serverState{
+		evalCtx: evalCtx,
+		mux:     mux,
+	})
+}
+
 // Listen initiates the configured http handler and start listing on given port.
 func (s *HTTPServer) Listen() {
 	if s.srv.Addr == "" {
@@ -120,13 +157,24 @@ func (s *HTTPServer) Listen() {
 	}
 
 	s.listener = ln
-	s.log.Infof("couper is serving: %s", ln.Addr().String())
+	if s.srv.TLSConfig != nil {
+		s.log.Infof("couper is serving tls: %s", ln.Addr().String())
+	} else {
+		s.log.Infof("couper is serving: %s", ln.Addr().String())
+	}
 
 	go s.listenForCtx()
 
 	go func() {
-		if err := s.srv.Serve(ln); err != nil {
-			s.log.Errorf("%s: %v", ln.Addr().String(), err.Error())
+		var serveErr error
+		if s.srv.TLSConfig != nil {
+			// Certificates are provided via TLSConfig.GetCertificate.
+			serveErr = s.srv.ServeTLS(ln, "", "")
+		} else {
+			serveErr = s.srv.Serve(ln)
+		}
+		if serveErr != nil {
+			s.log.Errorf("%s: %v", ln.Addr().String(), serveErr.Error())
 		}
 	}()
 }
@@ -165,7 +213,8 @@ func (s *HTTPServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	req.Host = s.getHost(req)
 
-	h := s.mux.FindHandler(req)
+	state := s.getState()
+	h := state.mux.FindHandler(req)
 	w := NewRWWrapper(rw,
 		transport.ReClientSupportsGZ.MatchString(
 			req.Header.Get(transport.AcceptEncodingHeader),
@@ -174,11 +223,11 @@ func (s *HTTPServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	rw = w
 
 	if err := s.setGetBody(h, req); err != nil {
-		s.mux.opts.ErrorTpl.ServeError(err).ServeHTTP(rw, req)
+		state.mux.opts.ErrorTpl.ServeError(err).ServeHTTP(rw, req)
 		return
 	}
 
-	ctx = s.evalCtx.WithClientRequest(req)
+	ctx = state.evalCtx.WithClientRequest(req)
 	clientReq := req.Clone(ctx)
 
 	s.accessLog.ServeHTTP(rw, clientReq, h, startTime)
@@ -192,7 +241,7 @@ func (s *HTTPServer) setGetBody(h http.Handler, req *http.Request) error {
 		outer = inner.Child()
 	}
 
-	if limitHandler, ok := h.(handler.EndpointLimit); ok {
+	if limitHandler, ok := outer.(handler.EndpointLimit); ok {
 		if err := eval.SetGetBody(req, limitHandler.RequestLimit()); err != nil {
 			return err
 		}
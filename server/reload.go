@@ -0,0 +1,42 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/avenga/couper/config/runtime"
+	"github.com/avenga/couper/eval"
+)
+
+// ReloadServerList applies the given server configuration to the listening servers.
+// Since the listeners are kept open, the configured ports must not differ. The
+// configuration gets applied only if all servers are able to reload.
+func ReloadServerList(list []*HTTPServer, evalCtx *eval.Context, srvConf runtime.ServerConfiguration) error {
+	if len(list) != len(srvConf) {
+		return fmt.Errorf("changed ports require a restart")
+	}
+
+	servers := make(map[*HTTPServer]*runtime.MuxOptions, len(list))
+	for _, srv := range list {
+		var muxOpts *runtime.MuxOptions
+		for port, opts := range srvConf {
+			if port.String() == srv.port {
+				muxOpts = opts
+				break
+			}
+		}
+
+		if muxOpts == nil {
+			return fmt.Errorf("port %s: changed ports require a restart", srv.port)
+		}
+
+		if err := srv.canReload(muxOpts); err != nil {
+			return err
+		}
+		servers[srv] = muxOpts
+	}
+
+	for srv, muxOpts := range servers {
+		srv.setState(evalCtx, muxOpts)
+	}
+	return nil
+}
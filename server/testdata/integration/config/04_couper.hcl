@@ -0,0 +1,36 @@
+server "sign" {
+  endpoint "/sign" {
+    access_control = ["JWTToken"]
+    proxy {
+      backend "test" {
+        set_request_headers = {
+          x-signed = jwt_sign("MyProfile", { aud = "the-service" })
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  jwt "JWTToken" {
+    header = "Authorization"
+    signature_algorithm = "HS256"
+    key = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
+  }
+
+  jwt_signing_profile "MyProfile" {
+    signature_algorithm = "HS256"
+    key = "Th3S1gn1ngK3y"
+    ttl = "1m"
+    claims = {
+      iss = "couper"
+      sub = req.ctx.JWTToken.sub
+      aud = "overridden"
+    }
+  }
+
+  backend "test" {
+    origin = env.COUPER_TEST_BACKEND_ADDR
+    path = "/anything"
+  }
+}
@@ -0,0 +1,27 @@
+server "jwks" {
+  endpoint "/jwks" {
+    access_control = ["JWKSToken"]
+    response {
+      headers = {
+        x-jwt-sub = req.ctx.JWKSToken.sub
+      }
+    }
+  }
+}
+
+definitions {
+  jwt "JWKSToken" {
+    header = "Authorization"
+    signature_algorithm = "RS256"
+    jwks_url = "${env.COUPER_TEST_JWKS_ADDR}/.well-known/jwks.json"
+    jwks_ttl = "1h"
+    backend = "jwks"
+  }
+
+  backend "jwks" {
+    origin = env.COUPER_TEST_JWKS_ADDR
+    set_request_headers = {
+      x-jwks-backend = "jwks"
+    }
+  }
+}
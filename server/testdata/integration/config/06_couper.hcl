@@ -0,0 +1,46 @@
+server "params" {
+  endpoint "/query" {
+    access_control = ["QueryToken"]
+    proxy {
+      backend "test" {
+        set_request_headers = {
+          x-client-post = req.post.access_token
+          x-client-query = req.query.access_token
+        }
+      }
+    }
+  }
+
+  endpoint "/form" {
+    access_control = ["PostToken"]
+    proxy {
+      backend "test" {
+        set_request_headers = {
+          x-client-post = req.post.access_token
+          x-client-query = req.query.access_token
+        }
+      }
+    }
+  }
+}
+
+definitions {
+  jwt "QueryToken" {
+    query_param = "access_token"
+    strip_token = true
+    signature_algorithm = "HS256"
+    key = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
+  }
+
+  jwt "PostToken" {
+    post_param = "access_token"
+    strip_token = true
+    signature_algorithm = "HS256"
+    key = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
+  }
+
+  backend "test" {
+    origin = env.COUPER_TEST_BACKEND_ADDR
+    path = "/anything"
+  }
+}
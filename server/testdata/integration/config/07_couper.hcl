@@ -0,0 +1,32 @@
+server "scopes" {
+  api {
+    base_path = "/api"
+    access_control = ["Scoped"]
+    required_scopes = "api"
+
+    endpoint "/items" {
+      required_scopes = {
+        get = "read"
+        delete = ["admin", "write"]
+      }
+      response {
+        body = "ok"
+      }
+    }
+
+    endpoint "/any" {
+      response {
+        body = "ok"
+      }
+    }
+  }
+}
+
+definitions {
+  jwt "Scoped" {
+    header = "Authorization"
+    signature_algorithm = "HS256"
+    key = "y0urS3cretT08eU5edF0rC0uPerInThe3xamp1e"
+    roles_claim = "realm_access.roles"
+  }
+}
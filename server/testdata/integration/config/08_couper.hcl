@@ -0,0 +1,27 @@
+server "oidc" {
+  spa {
+    access_control = ["Login"]
+    bootstrap_file = "../spa/app.html"
+    paths = ["/app/**", "/oidc/callback"]
+  }
+
+  endpoint "/userinfo" {
+    access_control = ["Login"]
+    response {
+      headers = {
+        x-sub = req.ctx.Login.sub
+      }
+    }
+  }
+}
+
+definitions {
+  oidc "Login" {
+    configuration_url = "${env.COUPER_TEST_OIDC_ADDR}/.well-known/openid-configuration"
+    client_id = "couper"
+    client_secret = "s3cr3t"
+    redirect_uri = "http://couper.local:8080/oidc/callback"
+    scope = "profile"
+    session_secret = "y0urS3ss10nS3cr3tF0rC0uPer"
+  }
+}
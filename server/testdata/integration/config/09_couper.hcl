@@ -0,0 +1,20 @@
+server "introspection" {
+  endpoint "/opaque" {
+    access_control = ["Opaque"]
+    required_scopes = "read"
+    response {
+      headers = {
+        x-sub = req.ctx.Opaque.sub
+      }
+    }
+  }
+}
+
+definitions {
+  introspection "Opaque" {
+    endpoint = "${env.COUPER_TEST_INTROSPECTION_ADDR}/introspect"
+    client_id = "couper"
+    client_secret = "s3cr3t"
+    ttl = "10s"
+  }
+}
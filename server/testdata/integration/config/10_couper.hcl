@@ -0,0 +1,24 @@
+server "api_key" {
+  endpoint "/partner" {
+    access_control = ["Partner"]
+    response {
+      headers = {
+        x-owner = req.ctx.Partner.owner
+        x-plan = req.ctx.Partner.plan
+      }
+    }
+  }
+}
+
+definitions {
+  api_key "Partner" {
+    query_param = "api_key"
+    key "acme" {
+      value = "sha256:2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+      metadata = {
+        owner = "ACME"
+        plan = "gold"
+      }
+    }
+  }
+}
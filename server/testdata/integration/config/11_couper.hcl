@@ -0,0 +1,34 @@
+server "ip_filter" {
+  access_control = ["Local"]
+
+  endpoint "/local" {
+    response {
+      body = "local"
+    }
+  }
+
+  endpoint "/office" {
+    access_control = ["Office"]
+    response {
+      body = "office"
+    }
+  }
+
+  endpoint "/public" {
+    disable_access_control = ["Local"]
+    response {
+      body = "public"
+    }
+  }
+}
+
+definitions {
+  ip_filter "Local" {
+    allow = ["127.0.0.0/8", "::1"]
+  }
+
+  ip_filter "Office" {
+    allow = ["10.0.0.0/8"]
+    deny = ["10.0.0.1"]
+  }
+}
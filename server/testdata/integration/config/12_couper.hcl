@@ -0,0 +1,48 @@
+server "access_control_group" {
+  access_control = ["Local"]
+
+  endpoint "/either" {
+    access_control = ["Either"]
+    response {
+      body = "either"
+    }
+  }
+
+  endpoint "/public" {
+    disable_access_control = ["Local"]
+    response {
+      body = "public"
+    }
+  }
+}
+
+definitions {
+  access_control_group "Local" {
+    all = ["Loopback"]
+  }
+
+  # references a group defined later on
+  access_control_group "Either" {
+    any = ["Partner", "Credentials"]
+  }
+
+  access_control_group "Credentials" {
+    all = ["Loopback", "User"]
+  }
+
+  api_key "Partner" {
+    header = "X-API-Key"
+    key "partner" {
+      value = "sha256:346e50af211b5135824bb2bb58fe0f9e6df228adcf10c58a37fbc46b57baee74"
+    }
+  }
+
+  basic_auth "User" {
+    user = "john"
+    password = "secret"
+  }
+
+  ip_filter "Loopback" {
+    allow = ["127.0.0.0/8", "::1"]
+  }
+}
@@ -0,0 +1,35 @@
+server "token_errors" {
+  endpoint "/token" {
+    access_control = ["Token"]
+    response {
+      body = "token"
+    }
+  }
+
+  endpoint "/cookie" {
+    access_control = ["Cookie"]
+    response {
+      body = "cookie"
+    }
+  }
+}
+
+definitions {
+  jwt "Token" {
+    header = "Authorization"
+    signature_algorithm = "HS256"
+    key = "s3cr3t"
+    realm = "couper"
+    claims = {
+      iss = "https://idp.example.com"
+    }
+    required_claims = ["sub"]
+    error_file = "../api_error.json"
+  }
+
+  jwt "Cookie" {
+    cookie = "token"
+    signature_algorithm = "HS256"
+    key = "s3cr3t"
+  }
+}
@@ -0,0 +1,50 @@
+server "rate_limit" {
+  rate_limit = ["PerClient"]
+
+  api {
+    base_path = "/api"
+    rate_limit = ["PerKey"]
+
+    endpoint "/keys" {
+      response {
+        body = "keys"
+      }
+    }
+  }
+
+  endpoint "/client" {
+    response {
+      body = "client"
+    }
+  }
+
+  endpoint "/protected" {
+    access_control = ["Key"]
+
+    response {
+      body = "protected"
+    }
+  }
+}
+
+definitions {
+  api_key "Key" {
+    header = "X-Key"
+
+    key "a" {
+      value = "a"
+    }
+  }
+
+  rate_limit "PerClient" {
+    limit = 6
+    window = "1m"
+  }
+
+  rate_limit "PerKey" {
+    algorithm = "token_bucket"
+    key = req.headers.x-api-key
+    limit = 2
+    window = "1m"
+  }
+}
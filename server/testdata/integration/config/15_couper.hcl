@@ -0,0 +1,71 @@
+server "shared-backends" {
+  endpoint "/lb/a" {
+    proxy {
+      backend = "lb"
+    }
+  }
+
+  endpoint "/lb/b" {
+    proxy {
+      backend = "lb"
+    }
+  }
+
+  endpoint "/throttle/a" {
+    proxy {
+      backend = "throttled"
+    }
+  }
+
+  endpoint "/throttle/b" {
+    proxy {
+      backend = "throttled"
+    }
+  }
+
+  endpoint "/breaker/a" {
+    proxy {
+      backend = "breaker"
+    }
+  }
+
+  endpoint "/breaker/b" {
+    proxy {
+      backend = "breaker"
+    }
+  }
+}
+
+definitions {
+  backend "lb" {
+    path = "/anything"
+
+    load_balancer {
+      upstream {
+        origin = env.COUPER_TEST_BACKEND_ADDR
+      }
+      upstream {
+        origin = "http://127.0.0.1:9"
+      }
+    }
+  }
+
+  backend "breaker" {
+    origin = "http://127.0.0.1:9"
+
+    circuit_breaker {
+      consecutive_failures = 2
+      open_timeout = "1m"
+    }
+  }
+
+  backend "throttled" {
+    origin = env.COUPER_TEST_BACKEND_ADDR
+    path = "/anything"
+
+    throttle {
+      requests_per_second = 0.1
+      max_wait = "100ms"
+    }
+  }
+}
@@ -0,0 +1,26 @@
+server "shared-oauth2" {
+  endpoint "/oauth2/a" {
+    proxy {
+      backend = "oauth2"
+    }
+  }
+
+  endpoint "/oauth2/b" {
+    proxy {
+      backend = "oauth2"
+    }
+  }
+}
+
+definitions {
+  backend "oauth2" {
+    origin = env.COUPER_TEST_BACKEND_ADDR
+    path = "/anything"
+
+    oauth2 {
+      client_id = "my-client"
+      client_secret = "my-secret"
+      token_endpoint = env.COUPER_TEST_TOKEN_ENDPOINT
+    }
+  }
+}
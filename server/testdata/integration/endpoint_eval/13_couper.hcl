@@ -0,0 +1,25 @@
+server "api" {
+  endpoint "/prefix/**" {
+    path = "/**"
+    proxy {
+      backend = "anything"
+    }
+  }
+
+  endpoint "/refined/{prefix}" {
+    proxy {
+      backend "anything" {
+        path_prefix = "/${req.path_params.prefix}"
+        path = "/thing"
+      }
+    }
+  }
+}
+
+definitions {
+  # backend origin within a definition block gets replaced with the integration test "anything" server.
+  backend "anything" {
+    origin = env.COUPER_TEST_BACKEND_ADDR
+    path_prefix = "/any"
+  }
+}
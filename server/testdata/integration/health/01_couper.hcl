@@ -0,0 +1,51 @@
+server "health" {
+  endpoint "/" {
+    proxy {
+      backend = "lb"
+    }
+
+    set_response_headers = {
+      x-health-state = backends.lb.health.state
+      x-health-healthy = backends.lb.health.healthy ? "yes" : "no"
+    }
+  }
+
+  endpoint "/down" {
+    proxy {
+      backend = "down"
+    }
+  }
+}
+
+definitions {
+  backend "lb" {
+    path = "/anything"
+
+    load_balancer {
+      upstream {
+        origin = env.COUPER_TEST_BACKEND_ADDR
+      }
+      upstream {
+        origin = "http://127.0.0.1:9"
+      }
+    }
+
+    health {
+      path = "/anything"
+      interval = "1s"
+      timeout = "100ms"
+    }
+  }
+
+  backend "down" {
+    origin = "http://127.0.0.1:9"
+
+    health {
+      interval = "1s"
+    }
+  }
+}
+
+settings {
+  health_include_backends = true
+}
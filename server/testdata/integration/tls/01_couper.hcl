@@ -0,0 +1,22 @@
+server "tls" {
+  hosts = ["*:8443", "example.com:8443"]
+
+  tls {
+    certificate_file = "localhost.crt"
+    key_file = "localhost.key"
+
+    certificate "example.com" {
+      certificate_file = "example.com.crt"
+      key_file = "example.com.key"
+    }
+  }
+
+  endpoint "/" {
+    response {
+      headers = {
+        x-tls-version = req.tls.version
+        x-tls-server-name = req.tls.server_name
+      }
+    }
+  }
+}
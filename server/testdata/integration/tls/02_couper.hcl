@@ -0,0 +1,20 @@
+server "tls" {
+  hosts = ["secure.example.com:8443"]
+
+  tls {
+    certificate_file = "localhost.crt"
+    key_file = "localhost.key"
+  }
+
+  endpoint "/" {
+    response {}
+  }
+}
+
+server "plain" {
+  hosts = ["plain.example.com:8443"]
+
+  endpoint "/" {
+    response {}
+  }
+}
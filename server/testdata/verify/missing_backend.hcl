@@ -0,0 +1,7 @@
+server "api" {
+  endpoint "/" {
+    proxy {
+      backend = "anything"
+    }
+  }
+}
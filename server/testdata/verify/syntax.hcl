@@ -0,0 +1,2 @@
+server "api" {
+  endpoint "/" {
@@ -0,0 +1,13 @@
+server "api" {
+  endpoint "/" {
+    proxy {
+      backend = "anything"
+    }
+  }
+}
+
+definitions {
+  backend "anything" {
+    origin = "http://127.0.0.1:1"
+  }
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// newTLSConfig creates a server side <*tls.Config> which selects
+// the certificate by the requested server name (SNI). The certificates are obtained
+// on every handshake to apply reloaded configurations.
+func newTLSConfig(certificatesFn func() map[string]*tls.Certificate, requestClientCert bool) *tls.Config {
+	clientAuth := tls.NoClientCert
+	if requestClientCert { // verification is done by the related access control
+		clientAuth = tls.RequestClientCert
+	}
+
+	return &tls.Config{
+		ClientAuth: clientAuth,
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			certificates := certificatesFn()
+			serverName := strings.TrimSuffix(strings.ToLower(hello.ServerName), ".")
+			if cert, ok := certificates[serverName]; ok {
+				return cert, nil
+			}
+			if cert, ok := certificates["*"]; ok {
+				return cert, nil
+			}
+			return nil, fmt.Errorf("tls: no certificate configured for server name: %q", hello.ServerName)
+		},
+		MinVersion: tls.VersionTLS12,
+	}
+}